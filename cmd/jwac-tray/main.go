@@ -2,37 +2,43 @@ package main
 
 import (
 	"log"
-	"os/user"
 	"path/filepath"
 
 	"github.com/getlantern/systray"
 	"github.com/rjeczalik/notify"
 
+	"github.com/andrskom/jwa-console/pkg/config"
 	"github.com/andrskom/jwa-console/pkg/creds"
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/issuecache"
 	"github.com/andrskom/jwa-console/pkg/jiraf"
-	"github.com/andrskom/jwa-console/pkg/storage/file"
+	"github.com/andrskom/jwa-console/pkg/profile"
 	"github.com/andrskom/jwa-console/pkg/timeline"
 	"github.com/andrskom/jwa-console/pkg/tray"
 )
 
 func main() {
-	dbFilePath, err := getDotRc()
+	db, err := filedb.InitJSON()
 	if err != nil {
-		log.Fatalf("Can't get db path: %s", err.Error())
+		log.Fatalf("Can't init db: %s", err.Error())
 	}
 
+	// timeline is replaced by rename on write, so dir is watched instead of the file.
 	c := make(chan notify.EventInfo, 1)
-	if err := notify.Watch(filepath.Join(dbFilePath, "timeline.json"), c, notify.Write); err != nil {
+	if err := notify.Watch(db.DirPath(), c, notify.Write, notify.Create, notify.Rename); err != nil {
 		log.Fatal(err)
 	}
 	defer notify.Stop(c)
 
 
-	db := file.New(dbFilePath, "init")
-	credsComponent := creds.New(db)
+	profiles := profile.NewComponent(db)
+	credsComponent := creds.New(db, profiles)
 	jiraFactory := jiraf.NewFactory(credsComponent)
+	cfg := config.NewComponent(db, profiles)
 
-	timelineComponent := timeline.NewComponent(db, jiraFactory)
+	issues := issuecache.NewComponent(db, jiraFactory, cfg)
+
+	timelineComponent := timeline.NewComponent(db, jiraFactory, cfg, profiles, issues)
 
 	greyAsset, err := tray.Asset("assets/grey.png")
 	if err != nil {
@@ -42,6 +48,11 @@ func main() {
 	if err != nil {
 		log.Fatalf("Can't read green asset: %s", err.Error())
 	}
+	// paused record is shown by red icon.
+	red, err := tray.Asset("assets/red.png")
+	if err != nil {
+		log.Fatalf("Can't read red asset: %s", err.Error())
+	}
 
 	systray.Run(func() {
 		cur, err := timelineComponent.GetCurrent()
@@ -52,15 +63,20 @@ func main() {
 				log.Fatalf("can't read db: %s", err.Error())
 			}
 		} else {
-			if cur.IsFinished() {
+			switch {
+			case cur.IsFinished():
 				systray.SetIcon(greyAsset)
-			} else {
+			case cur.IsPaused():
+				systray.SetIcon(red)
+			default:
 				systray.SetIcon(yellow)
 			}
 		}
 
 		for {
-			<-c
+			if name := filepath.Base((<-c).Path()); name != "timeline.json" && name != "events.jsonl" {
+				continue
+			}
 			cur, err := timelineComponent.GetCurrent()
 			if err != nil {
 				if err == timeline.ErrTimelineEmpty {
@@ -69,9 +85,12 @@ func main() {
 				}
 				log.Fatalf("can't read db: %s", err.Error())
 			}
-			if cur.IsFinished() {
+			switch {
+			case cur.IsFinished():
 				systray.SetIcon(greyAsset)
-			} else {
+			case cur.IsPaused():
+				systray.SetIcon(red)
+			default:
 				systray.SetIcon(yellow)
 			}
 		}
@@ -79,12 +98,3 @@ func main() {
 
 	})
 }
-
-
-func getDotRc() (string, error) {
-	usr, err := user.Current()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(usr.HomeDir, ".jwarc"), nil
-}
@@ -8,16 +8,21 @@ import (
 	"os/user"
 	"path/filepath"
 	"sort"
+	"strings"
 	"syscall"
 
+	"github.com/fatih/color"
 	"github.com/urfave/cli"
 
 	"github.com/andrskom/jwa-console/pkg/action"
 	"github.com/andrskom/jwa-console/pkg/action/login"
 	"github.com/andrskom/jwa-console/pkg/config"
 	"github.com/andrskom/jwa-console/pkg/creds"
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/issuecache"
 	"github.com/andrskom/jwa-console/pkg/jiraf"
-	"github.com/andrskom/jwa-console/pkg/storage/file"
+	"github.com/andrskom/jwa-console/pkg/migration"
+	"github.com/andrskom/jwa-console/pkg/profile"
 	"github.com/andrskom/jwa-console/pkg/tag"
 	"github.com/andrskom/jwa-console/pkg/timeline"
 )
@@ -28,20 +33,74 @@ func main() {
 	app.Version = "v0.1.0"
 	app.Name = "jwac"
 	app.Usage = "Jira worklog assistant console"
-	dbFilePath, err := getDotRc()
+	db, err := filedb.InitJSON()
 	if err != nil {
-		log.Fatalf("Can't get db path: %s", err.Error())
+		log.Fatalf("Can't init db: %s", err.Error())
 	}
-	db := file.New(dbFilePath, "init")
-	credsComponent := creds.New(db)
+	profiles := profile.NewComponent(db)
+	credsComponent := creds.New(db, profiles)
 	jiraFactory := jiraf.NewFactory(credsComponent)
-	cfg := config.NewComponent(db)
-	if err := cfg.Init(); err != nil {
-		log.Fatalln(err)
-	}
+	cfg := config.NewComponent(db, profiles)
 	tagComponent := tag.NewComponent(cfg)
 
-	timelineComponent := timeline.NewComponent(db, jiraFactory, cfg)
+	issues := issuecache.NewComponent(db, jiraFactory, cfg)
+	timelineComponent := timeline.NewComponent(db, jiraFactory, cfg, profiles, issues)
+	timelineComponent.SetCommand(strings.Join(append([]string{app.Name}, os.Args[1:]...), " "))
+
+	migrations := migration.NewRegistry(db, profiles)
+	migrations.Register(timelineComponent.Documents()...)
+	migrations.Register(cfg.Document(), credsComponent.Document(), issues.Document())
+
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "profile",
+			Usage: "Profile of jira, the current profile is used if not set",
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "Output format of show, archive, status, report and plan of publish: text, json, yaml or csv",
+			Value: string(action.OutputText),
+		},
+	}
+	app.Before = func(c *cli.Context) error {
+		format, err := action.ParseOutputFormat(c.GlobalString("output"))
+		if err != nil {
+			return err
+		}
+		if format != action.OutputText {
+			color.NoColor = true
+		}
+		if name := c.GlobalString("profile"); len(name) > 0 {
+			known, err := profiles.Get()
+			if err != nil {
+				return err
+			}
+			if !known.Has(name) {
+				return fmt.Errorf("unknown profile '%s', use 'jwac login --profile %s' for creating it", name, name)
+			}
+			if err := profiles.SetOverride(name); err != nil {
+				return err
+			}
+		}
+		oldDir, err := getDotRc()
+		if err != nil {
+			return err
+		}
+		if err := migrations.ImportDir(oldDir); err != nil {
+			return err
+		}
+		isInit, err := timelineComponent.IsInit()
+		if err != nil || !isInit {
+			return err
+		}
+		if err := migrations.Run(); err != nil {
+			return err
+		}
+		if err := timelineComponent.ArchivePublished(); err != nil {
+			return err
+		}
+		return cfg.Init()
+	}
 
 	startFlags := []cli.Flag{
 		cli.StringFlag{
@@ -60,6 +119,16 @@ func main() {
 			Name:  "nt",
 			Usage: "No tags for description",
 		},
+		cli.BoolFlag{
+			Name:  "refresh",
+			Usage: "Fetch task from jira even if it's cached",
+		},
+	}
+	issueCompletion := action.IssueCompletion(profiles, issues)
+
+	noTransitionFlag := cli.BoolFlag{
+		Name:  "no-transition",
+		Usage: "Don't change status of task to configured autoChangeStatusTo",
 	}
 
 	app.Commands = []cli.Command{
@@ -67,38 +136,76 @@ func main() {
 			Name:  "init",
 			Usage: "Init application",
 			Action: func(c *cli.Context) error {
-				if err := db.Init(); err != nil {
-					return err
-				}
 				if err := timelineComponent.Init(); err != nil {
 					return err
 				}
-				return nil
+				return cfg.Init()
 			},
 		},
 		{
-			Name:   "login",
-			Usage:  "Login to jira",
-			Action: login.Login(credsComponent),
+			Name:  "login",
+			Usage: "Login to jira",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name: "method",
+					Usage: `Auth method:
+basic - username and password,
+api-token - email and api token of Atlassian Cloud,
+pat - personal access token of Jira Server and Data Center.`,
+					Value: string(creds.AuthBasic),
+				},
+				cli.StringFlag{
+					Name:  "profile",
+					Usage: "Profile for credentials, the current profile is used if not set",
+				},
+			},
+			Action: login.Login(credsComponent, profiles),
 		},
 		{
-			Name:   "start",
-			Usage:  "Start track task",
-			Flags:  startFlags,
-			Action: action.Start(timelineComponent, tagComponent),
+			Name:         "start",
+			Usage:        "Start track task",
+			Flags:        append(startFlags, noTransitionFlag),
+			BashComplete: issueCompletion,
+			Action:       action.Start(timelineComponent, tagComponent),
+		},
+		{
+			Name:  "add",
+			Usage: "Add finished record of task to timeline retroactively",
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name:  "start-time",
+					Usage: "Start time in format '2006-01-02T15:04'",
+				},
+				cli.StringFlag{
+					Name:  "finish-time",
+					Usage: "Finish time in format '2006-01-02T15:04'",
+				},
+			}, startFlags...),
+			BashComplete: issueCompletion,
+			Action:       action.Add(timelineComponent, tagComponent),
 		},
 		{
 			Name:   "stop",
 			Usage:  "Stop track task",
 			Action: action.Stop(timelineComponent),
 		},
+		{
+			Name:   "pause",
+			Usage:  "Pause track task, the task record stays open",
+			Action: action.Pause(timelineComponent),
+		},
+		{
+			Name:   "resume",
+			Usage:  "Resume track paused task",
+			Action: action.Resume(timelineComponent),
+		},
 		{
 			Name:  "start-and-wait",
-			Flags: startFlags,
+			Flags: append(startFlags, noTransitionFlag),
 			Usage: "Start task and stop tracking when u send SIGTERM",
 			Action: func(c *cli.Context) (err error) {
 				started := false
-				signalCh := make(chan os.Signal)
+				signalCh := make(chan os.Signal, 1)
 				signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
 
 				if err := action.Start(timelineComponent, tagComponent)(c); err != nil {
@@ -117,7 +224,7 @@ func main() {
 			Name:    "show",
 			Aliases: []string{"log", "ps"},
 			Usage:   "Show logged",
-			Action:  action.Show(timelineComponent),
+			Action:  action.Show(timelineComponent, issues),
 		},
 		{
 			Name:   "status",
@@ -127,8 +234,50 @@ func main() {
 		{
 			Name:    "publish",
 			Aliases: []string{"push"},
-			Usage:   "Status of current task",
-			Action:  action.Publish(timelineComponent),
+			Usage:   "Publish worklogs to jira",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Show plan of publishing without sending",
+				},
+				cli.BoolFlag{
+					Name:  "y",
+					Usage: "Publish without confirmation",
+				},
+				cli.StringFlag{
+					Name: "running",
+					Usage: `What to do with the running task:
+keep - leave it in timeline untouched,
+stop - stop it and publish,
+split - publish it up to now and keep tracking.`,
+					Value: string(timeline.RunningKeep),
+				},
+				cli.BoolFlag{
+					Name:  "aggregate",
+					Usage: "Publish one worklog per issue per day",
+				},
+				cli.StringFlag{
+					Name:  "records",
+					Usage: "Publish only range of records, in format 'N-M' or 'N'",
+				},
+				cli.StringFlag{
+					Name:  "from",
+					Usage: "Publish only records started from the date in format '2006-01-02'",
+				},
+				cli.StringFlag{
+					Name:  "until",
+					Usage: "Publish only records started until the end of date in format '2006-01-02'",
+				},
+				cli.StringSliceFlag{
+					Name:  "issue",
+					Usage: "Publish only records of issue, can be repeated",
+				},
+				cli.StringSliceFlag{
+					Name:  "tag",
+					Usage: "Publish only records with tag, can be repeated",
+				},
+			},
+			Action: action.Publish(timelineComponent),
 		},
 		{
 			Name:   "completion",
@@ -163,9 +312,36 @@ func main() {
 			Action: action.Edit(timelineComponent),
 		},
 		{
-			Name:  "change",
-			Usage: "Change to next task, equal to stop and start",
-			Flags: startFlags,
+			Name:    "rm",
+			Aliases: []string{"remove"},
+			Usage:   "Remove work record",
+			Action:  action.Remove(timelineComponent),
+		},
+		{
+			Name:  "split",
+			Usage: "Split work record to two records at time",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "at",
+					Usage: "Split time in format '2006-01-02T15:04'",
+				},
+				cli.StringFlag{
+					Name:  "task",
+					Usage: "Task key of the second record, the same task if not set",
+				},
+			},
+			Action: action.Split(timelineComponent),
+		},
+		{
+			Name:   "merge",
+			Usage:  "Merge two adjacent work records of the same task",
+			Action: action.Merge(timelineComponent),
+		},
+		{
+			Name:         "change",
+			Usage:        "Change to next task, equal to stop and start",
+			Flags:        append(startFlags, noTransitionFlag),
+			BashComplete: issueCompletion,
 			Action: func(c *cli.Context) error {
 				if err := action.Stop(timelineComponent)(c); err != nil {
 					return err
@@ -176,6 +352,105 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:   "undo",
+			Usage:  "Undo the last change of timeline",
+			Action: action.Undo(timelineComponent),
+		},
+		{
+			Name:   "redo",
+			Usage:  "Redo the last undone change of timeline",
+			Action: action.Redo(timelineComponent),
+		},
+		{
+			Name:   "history",
+			Usage:  "List of recent changes of timeline",
+			Action: action.History(timelineComponent),
+		},
+		{
+			Name:  "archive",
+			Usage: "Show records of past days from archive and timeline",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "from",
+					Usage: "Show records started from the date in format '2006-01-02', a week before --to if not set",
+				},
+				cli.StringFlag{
+					Name:  "to",
+					Usage: "Show records started until the end of date in format '2006-01-02', today if not set",
+				},
+				cli.StringSliceFlag{
+					Name:  "issue",
+					Usage: "Show only records of issue, can be repeated",
+				},
+				cli.StringSliceFlag{
+					Name:  "tag",
+					Usage: "Show only records with tag, can be repeated",
+				},
+			},
+			Action: action.Archive(timelineComponent, issues),
+		},
+		{
+			Name:  "export",
+			Usage: "Export records of timeline and archive",
+			Subcommands: []cli.Command{
+				{
+					Name:  "ics",
+					Usage: "Export records as iCalendar events, the running record ends now",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "from",
+							Usage: "Export records started from the date in format '2006-01-02', a week before --to if not set",
+						},
+						cli.StringFlag{
+							Name:  "to",
+							Usage: "Export records started until the end of date in format '2006-01-02', today if not set",
+						},
+						cli.StringSliceFlag{
+							Name:  "issue",
+							Usage: "Export only records of issue, can be repeated",
+						},
+						cli.StringSliceFlag{
+							Name:  "tag",
+							Usage: "Export only records with tag, can be repeated",
+						},
+						cli.StringFlag{
+							Name:  "file",
+							Usage: "File for calendar, stdout if not set",
+						},
+					},
+					Action: action.ExportICS(timelineComponent),
+				},
+			},
+		},
+		{
+			Name:      "report",
+			Usage:     "Report of activity by issues, projects, epics and tags",
+			ArgsUsage: "day|week|month",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "date",
+					Usage: "Date of period in format '2006-01-02', today if not set",
+				},
+			},
+			Action: action.Report(timelineComponent),
+		},
+		{
+			Name:  "profile",
+			Usage: "Profiles of jira",
+			Subcommands: []cli.Command{
+				{
+					Name:   "ls",
+					Usage:  "List of profiles",
+					Action: action.ProfileList(profiles),
+				},
+				{
+					Name:   "use",
+					Usage:  "Make profile current",
+					Action: action.ProfileUse(profiles),
+				},
+			},
+		},
 		{
 			Name:  "config",
 			Usage: "Configuration",
@@ -217,6 +492,7 @@ Use ',' as separator for slice of strings. `,
 	}
 }
 
+// getDotRc returns dir of data of old versions, it's imported to db on start.
 func getDotRc() (string, error) {
 	usr, err := user.Current()
 	if err != nil {
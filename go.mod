@@ -23,4 +23,5 @@ require (
 	github.com/stretchr/testify v1.3.0
 	github.com/urfave/cli v1.20.0
 	golang.org/x/crypto v0.0.0-20190426145343-a29dc8fdc734
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,64 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"github.com/andrskom/jwa-console/pkg/tag"
+	"github.com/andrskom/jwa-console/pkg/timeline"
+)
+
+func Add(
+	timelineComponent *timeline.Component,
+	tagComponent *tag.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		taskID := c.Args().Get(0)
+		if taskID == "" {
+			return errors.New("u must set number of task as last args")
+		}
+		if len(c.String("start-time")) == 0 || len(c.String("finish-time")) == 0 {
+			return errors.New("u must set both start-time and finish-time")
+		}
+		st, err := time.ParseInLocation(timeLayout, c.String("start-time"), time.Local)
+		if err != nil {
+			return err
+		}
+		ft, err := time.ParseInLocation(timeLayout, c.String("finish-time"), time.Local)
+		if err != nil {
+			return err
+		}
+
+		var opts *timeline.StartOpts
+		if len(c.String("m")) > 0 || c.Bool("pd") || c.Bool("refresh") {
+			opts = &timeline.StartOpts{
+				Description:        c.String("m"),
+				UsePrevDescription: c.Bool("pd"),
+				Refresh:            c.Bool("refresh"),
+			}
+		}
+
+		model, err := timelineComponent.BuildModel(taskID, opts)
+		if err != nil {
+			return err
+		}
+		if err := tagComponent.SetTag(c.String("t"), c.Bool("nt"), model); err != nil {
+			return err
+		}
+		model.StartTime = st
+		model.FinishTime = ft
+		model.Finished = true
+
+		num, err := timelineComponent.Add(model)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(`Add record %d for task %s %s
+`, num, model.Issue.Key, model.Issue.Summary)
+		return nil
+	}
+}
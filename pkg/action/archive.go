@@ -0,0 +1,62 @@
+package action
+
+import (
+	"time"
+
+	"github.com/urfave/cli"
+
+	"github.com/andrskom/jwa-console/pkg/issuecache"
+	"github.com/andrskom/jwa-console/pkg/timeline"
+)
+
+// archiveDays is a number of days shown by archive if range isn't set.
+const archiveDays = 7
+
+// Archive shows records of past days from archive and timeline, records which aren't published yet are included.
+func Archive(
+	timelineComponent *timeline.Component,
+	issues *issuecache.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		filter, err := getArchiveFilter(c)
+		if err != nil {
+			return err
+		}
+		model, err := timelineComponent.GetWithArchive(filter)
+		if err != nil {
+			return err
+		}
+		if format := getOutputFormat(c); format != OutputText {
+			return writeTimeline(format, timelineComponent, issues, model)
+		}
+		return drawTimeline(timelineComponent, issues, model)
+	}
+}
+
+// getArchiveFilter returns filter of archive, the last week is used if dates aren't set.
+func getArchiveFilter(c *cli.Context) (*timeline.PublishFilter, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if len(c.String("to")) > 0 {
+		date, err := time.ParseInLocation(dateLayout, c.String("to"), time.Local)
+		if err != nil {
+			return nil, err
+		}
+		to = date
+	}
+	from := to.AddDate(0, 0, 1-archiveDays)
+	if len(c.String("from")) > 0 {
+		date, err := time.ParseInLocation(dateLayout, c.String("from"), time.Local)
+		if err != nil {
+			return nil, err
+		}
+		from = date
+	}
+
+	return &timeline.PublishFilter{
+		From:   from,
+		Until:  to.AddDate(0, 0, 1),
+		Issues: c.StringSlice("issue"),
+		Tags:   c.StringSlice("tag"),
+	}, nil
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 
 	"github.com/urfave/cli"
+
+	"github.com/andrskom/jwa-console/pkg/issuecache"
+	"github.com/andrskom/jwa-console/pkg/profile"
 )
 
 func Completion() func(c *cli.Context) error {
@@ -52,3 +55,26 @@ unset PROG`
 		return nil
 	}
 }
+
+// IssueCompletion completes keys of cached issues of the current profile.
+func IssueCompletion(
+	profiles *profile.Component,
+	issues *issuecache.Component,
+) func(c *cli.Context) {
+	return func(c *cli.Context) {
+		if c.NArg() > 0 {
+			return
+		}
+		name, err := profiles.Current()
+		if err != nil {
+			return
+		}
+		list, err := issues.List(name)
+		if err != nil {
+			return
+		}
+		for _, issue := range list {
+			fmt.Println(issue.Key)
+		}
+	}
+}
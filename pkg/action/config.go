@@ -31,14 +31,9 @@ func Config(
 				return errors.New("you must use ':' as separator for key and value")
 			}
 
-			model, err := cfg.GetCfg()
-			if err != nil {
-				return err
-			}
-			if err := model.Set(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])); err != nil {
-				return err
-			}
-			return cfg.Save(model)
+			return cfg.Update(func(model *config.Model) error {
+				return model.Set(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+			})
 		}
 		return nil
 	}
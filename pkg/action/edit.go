@@ -10,16 +10,22 @@ import (
 	"github.com/andrskom/jwa-console/pkg/timeline"
 )
 
+const timeLayout = "2006-01-02T15:04"
+
+func getRecordNum(c *cli.Context, i int) (int, error) {
+	if len(c.Args().Get(i)) == 0 {
+		return 0, errors.New(
+			"u should set number of record as last args(run jwac show for see numbers of records)",
+		)
+	}
+	return strconv.Atoi(c.Args().Get(i))
+}
+
 func Edit(
 	timelineComponent *timeline.Component,
 ) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		if len(c.Args().Get(0)) == 0 {
-			return errors.New(
-				"u should set number of record as last args(run jwac show for see numbers of records)",
-			)
-		}
-		num, err := strconv.Atoi(c.Args().Get(0))
+		num, err := getRecordNum(c, 0)
 		if err != nil {
 			return err
 		}
@@ -36,14 +42,14 @@ func Edit(
 			*opts.Description = ""
 		}
 		if len(c.String("start-time")) > 0 {
-			st, err := time.ParseInLocation("2006-01-02T15:04", c.String("start-time"), time.Local)
+			st, err := time.ParseInLocation(timeLayout, c.String("start-time"), time.Local)
 			if err != nil {
 				return err
 			}
 			opts.StartTime = &st
 		}
 		if len(c.String("finish-time")) > 0 {
-			ft, err := time.ParseInLocation("2006-01-02T15:04", c.String("finish-time"), time.Local)
+			ft, err := time.ParseInLocation(timeLayout, c.String("finish-time"), time.Local)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,75 @@
+package action
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"github.com/andrskom/jwa-console/pkg/ics"
+	"github.com/andrskom/jwa-console/pkg/timeline"
+)
+
+// ExportICS writes records of timeline and archive as events of calendar.
+// The running record ends now.
+func ExportICS(
+	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		filter, err := getArchiveFilter(c)
+		if err != nil {
+			return err
+		}
+		model, err := timelineComponent.GetWithArchive(filter)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		events := make([]*ics.Event, 0, len(model.List))
+		for _, m := range model.List {
+			events = append(events, newICSEvent(m, now))
+		}
+
+		file := c.String("file")
+		if len(file) == 0 {
+			return ics.Write(os.Stdout, events, now)
+		}
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		if err := ics.Write(f, events, now); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("%d records exported to %s\n", len(events), file)
+		return nil
+	}
+}
+
+func newICSEvent(m *timeline.Model, now time.Time) *ics.Event {
+	end := now
+	if m.IsFinished() {
+		end = m.FinishTime
+	}
+	description := make([]string, 0, 2)
+	for _, part := range []string{m.Tag, m.Description} {
+		if len(part) > 0 {
+			description = append(description, part)
+		}
+	}
+	return &ics.Event{
+		UID:         strconv.FormatInt(m.StartTime.UnixNano(), 10) + "-" + m.Issue.Key + "@jwac",
+		Summary:     strings.TrimSpace(m.Issue.Key + " " + m.Issue.Summary),
+		Description: strings.Join(description, "\n"),
+		Start:       m.StartTime,
+		End:         end,
+	}
+}
@@ -0,0 +1,60 @@
+package action
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"github.com/andrskom/jwa-console/pkg/timeline"
+)
+
+func Undo(
+	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		entry, err := timelineComponent.Undo()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Undo '%s' from %s\n", entry.Command, entry.Time.Format(time.RFC822))
+		return nil
+	}
+}
+
+func Redo(
+	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		entry, err := timelineComponent.Redo()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Redo '%s' from %s\n", entry.Command, entry.Time.Format(time.RFC822))
+		return nil
+	}
+}
+
+func History(
+	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		journal, err := timelineComponent.History()
+		if err != nil {
+			return err
+		}
+		if len(journal.Entries) == 0 && len(journal.Undone) == 0 {
+			warnColor.Println(`Nothing`)
+			return nil
+		}
+		for i := 0; i < len(journal.Undone); i++ {
+			entry := journal.Undone[i]
+			doNothingColor.Printf("   %s %s (undone)\n", entry.Time.Format(time.RFC822), entry.Command)
+		}
+		for i := len(journal.Entries) - 1; i >= 0; i-- {
+			entry := journal.Entries[i]
+			fmt.Printf("%2d %s %s\n", len(journal.Entries)-1-i, entry.Time.Format(time.RFC822), entry.Command)
+		}
+		return nil
+	}
+}
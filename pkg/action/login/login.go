@@ -7,52 +7,90 @@ import (
 	"strings"
 	"syscall"
 
-	"github.com/andygrunwald/go-jira"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/andrskom/jwa-console/pkg/creds"
+	"github.com/andrskom/jwa-console/pkg/jiraf"
+	"github.com/andrskom/jwa-console/pkg/profile"
 )
 
-func Login(credsComponent *creds.Component) func(c *cli.Context) error {
+func Login(credsComponent *creds.Component, profiles *profile.Component) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
 		addr := c.Args().First()
 		if addr == "" {
 			return errors.New("u must set host of jira as last arg")
 		}
-		reader := bufio.NewReader(os.Stdin)
-		fmt.Println("Username:")
-		login, err := reader.ReadBytes('\n')
-		if err != nil {
-			return err
+		if len(c.String("profile")) > 0 {
+			if err := profiles.SetOverride(c.String("profile")); err != nil {
+				return err
+			}
 		}
-		fmt.Println("Password:")
-		pass, err := terminal.ReadPassword(syscall.Stdin)
+		method, err := creds.ParseAuthMethod(c.String("method"))
 		if err != nil {
 			return err
 		}
 
 		model := creds.Model{
-			Username: strings.TrimRight(string(login), "\n"),
-			Addr:     addr,
-			Password: string(pass),
+			Method: method,
+			Addr:   addr,
 		}
-
-		tp := jira.BasicAuthTransport{
-			Username: model.Username,
-			Password: model.Password,
+		reader := bufio.NewReader(os.Stdin)
+		switch method {
+		case creds.AuthBasic, creds.AuthAPIToken:
+			if method == creds.AuthBasic {
+				fmt.Println("Username:")
+			} else {
+				fmt.Println("Email:")
+			}
+			login, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			model.Username = strings.TrimSpace(login)
+			if method == creds.AuthBasic {
+				fmt.Println("Password:")
+			} else {
+				fmt.Println("API token:")
+			}
+			if model.Password, err = readSecret(); err != nil {
+				return err
+			}
+		case creds.AuthPAT:
+			fmt.Println("Personal access token:")
+			if model.Token, err = readSecret(); err != nil {
+				return err
+			}
 		}
 
-		jiraClient, err := jira.NewClient(tp.Client(), model.Addr)
+		jiraClient, err := jiraf.BuildByCredsModel(&model)
 		if err != nil {
 			return err
 		}
 
 		if _, resp, err := jiraClient.User.GetSelf(); err != nil {
-			 return fmt.Errorf("unexpected error code from jira: %d", resp.StatusCode)
+			if resp == nil {
+				return err
+			}
+			return fmt.Errorf("unexpected error code from jira: %d", resp.StatusCode)
 		}
 
-		return credsComponent.Save(&model)
+		name, err := profiles.Current()
+		if err != nil {
+			return err
+		}
+		if err := profiles.Add(name); err != nil {
+			return err
+		}
+		return credsComponent.SaveFor(name, &model)
+	}
+}
+
+func readSecret() (string, error) {
+	secret, err := terminal.ReadPassword(syscall.Stdin)
+	if err != nil {
+		return "", err
 	}
+	return string(secret), nil
 }
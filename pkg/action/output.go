@@ -0,0 +1,417 @@
+package action
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+
+	"github.com/andrskom/jwa-console/pkg/issuecache"
+	"github.com/andrskom/jwa-console/pkg/timeline"
+)
+
+// OutputFormat is a format of read commands, every format except text is machine readable and has no colors.
+type OutputFormat string
+
+const (
+	OutputText OutputFormat = "text"
+	OutputJSON OutputFormat = "json"
+	OutputYAML OutputFormat = "yaml"
+	OutputCSV  OutputFormat = "csv"
+)
+
+func ParseOutputFormat(val string) (OutputFormat, error) {
+	switch f := OutputFormat(val); f {
+	case OutputText, OutputJSON, OutputYAML, OutputCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unexpected output format '%s', expected one of: text, json, yaml, csv", val)
+	}
+}
+
+func getOutputFormat(c *cli.Context) OutputFormat {
+	if f, err := ParseOutputFormat(c.GlobalString("output")); err == nil {
+		return f
+	}
+	return OutputText
+}
+
+// writeOutput writes view as json or yaml, csv is written from rows, the first row is a header.
+func writeOutput(w io.Writer, format OutputFormat, view interface{}, rows [][]string) error {
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(view, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case OutputYAML:
+		data, err := yaml.Marshal(view)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(w, string(data))
+		return err
+	case OutputCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	default:
+		return fmt.Errorf("unexpected output format '%s'", format)
+	}
+}
+
+// PauseView is a pause of record in machine readable output.
+type PauseView struct {
+	Start  time.Time  `json:"start" yaml:"start"`
+	Finish *time.Time `json:"finish,omitempty" yaml:"finish,omitempty"`
+}
+
+// RecordView is a record of timeline in machine readable output, durations are in seconds.
+type RecordView struct {
+	Num          int          `json:"num" yaml:"num"`
+	Profile      string       `json:"profile" yaml:"profile"`
+	IssueKey     string       `json:"issueKey" yaml:"issueKey"`
+	IssueSummary string       `json:"issueSummary" yaml:"issueSummary"`
+	Project      string       `json:"project" yaml:"project"`
+	Epic         string       `json:"epic" yaml:"epic"`
+	Tag          string       `json:"tag" yaml:"tag"`
+	Description  string       `json:"description" yaml:"description"`
+	Start        time.Time    `json:"start" yaml:"start"`
+	Finish       *time.Time   `json:"finish,omitempty" yaml:"finish,omitempty"`
+	Finished     bool         `json:"finished" yaml:"finished"`
+	Paused       bool         `json:"paused" yaml:"paused"`
+	WorklogID    string       `json:"worklogId,omitempty" yaml:"worklogId,omitempty"`
+	Pauses       []*PauseView `json:"pauses" yaml:"pauses"`
+	// ActivitySeconds is a duration without pauses, the running record lasts till now.
+	ActivitySeconds int64 `json:"activitySeconds" yaml:"activitySeconds"`
+	PausesSeconds   int64 `json:"pausesSeconds" yaml:"pausesSeconds"`
+	// GapSeconds is a time without activity between finish of the previous record and start of the record.
+	GapSeconds int64 `json:"gapSeconds" yaml:"gapSeconds"`
+}
+
+// TaskView is a sum of records of issue in machine readable output.
+type TaskView struct {
+	IssueKey        string `json:"issueKey" yaml:"issueKey"`
+	Summary         string `json:"summary" yaml:"summary"`
+	Status          string `json:"status" yaml:"status"`
+	ActivitySeconds int64  `json:"activitySeconds" yaml:"activitySeconds"`
+	// PublishSeconds is a sum of rounded durations of finished records which will be sent.
+	PublishSeconds int64 `json:"publishSeconds" yaml:"publishSeconds"`
+}
+
+// TimelineView is an output of show and archive.
+type TimelineView struct {
+	Records         []*RecordView `json:"records" yaml:"records"`
+	Tasks           []*TaskView   `json:"tasks" yaml:"tasks"`
+	ActivitySeconds int64         `json:"activitySeconds" yaml:"activitySeconds"`
+	PublishSeconds  int64         `json:"publishSeconds" yaml:"publishSeconds"`
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
+
+func newRecordView(num int, m *timeline.Model, prev *timeline.Model) *RecordView {
+	v := &RecordView{
+		Num:           num,
+		Profile:       m.GetProfile(),
+		IssueKey:      m.Issue.Key,
+		IssueSummary:  m.Issue.Summary,
+		Project:       m.Issue.Project,
+		Epic:          m.Issue.Epic,
+		Tag:           m.Tag,
+		Description:   m.Description,
+		Start:         m.StartTime,
+		Finished:      m.IsFinished(),
+		Paused:        m.IsPaused(),
+		WorklogID:     m.WorklogID,
+		Pauses:        make([]*PauseView, 0, len(m.Pauses)),
+		PausesSeconds: seconds(m.PausesDuration()),
+	}
+	for _, p := range m.Pauses {
+		pv := &PauseView{Start: p.StartTime}
+		if p.IsFinished() {
+			finish := p.FinishTime
+			pv.Finish = &finish
+		}
+		v.Pauses = append(v.Pauses, pv)
+	}
+	if m.IsFinished() {
+		finish := m.FinishTime
+		v.Finish = &finish
+		v.ActivitySeconds = seconds(m.Duration())
+	} else {
+		v.ActivitySeconds = seconds(m.ActivityDuration())
+	}
+	if prev != nil {
+		v.GapSeconds = seconds(m.StartTime.Sub(prev.FinishTime))
+	}
+	return v
+}
+
+func newTimelineView(
+	timelineComponent *timeline.Component,
+	issues *issuecache.Component,
+	model *timeline.Timeline,
+) (*TimelineView, error) {
+	v := &TimelineView{Records: make([]*RecordView, 0, len(model.List)), Tasks: make([]*TaskView, 0)}
+	var prev *timeline.Model
+	for i, m := range model.List {
+		rv := newRecordView(i, m, prev)
+		v.Records = append(v.Records, rv)
+		v.ActivitySeconds += rv.ActivitySeconds
+		prev = m
+	}
+
+	rules, err := timelineComponent.RoundingFor(model)
+	if err != nil {
+		return nil, err
+	}
+	for key, data := range model.GetDurationsByTasks(rules) {
+		v.Tasks = append(v.Tasks, &TaskView{
+			IssueKey:        key,
+			Summary:         data.Summary,
+			Status:          getCachedStatus(issues, data.Profile, key),
+			ActivitySeconds: seconds(data.Duration),
+			PublishSeconds:  seconds(data.Rounded),
+		})
+		v.PublishSeconds += seconds(data.Rounded)
+	}
+	sort.Slice(v.Tasks, func(i, j int) bool {
+		return v.Tasks[i].IssueKey < v.Tasks[j].IssueKey
+	})
+	return v, nil
+}
+
+var recordHeader = []string{
+	"num", "profile", "issueKey", "issueSummary", "project", "epic", "tag", "description",
+	"start", "finish", "finished", "paused", "worklogId", "activitySeconds", "pausesSeconds", "gapSeconds",
+}
+
+func (v *RecordView) row() []string {
+	finish := ""
+	if v.Finish != nil {
+		finish = v.Finish.Format(time.RFC3339)
+	}
+	return []string{
+		strconv.Itoa(v.Num),
+		v.Profile,
+		v.IssueKey,
+		v.IssueSummary,
+		v.Project,
+		v.Epic,
+		v.Tag,
+		v.Description,
+		v.Start.Format(time.RFC3339),
+		finish,
+		strconv.FormatBool(v.Finished),
+		strconv.FormatBool(v.Paused),
+		v.WorklogID,
+		strconv.FormatInt(v.ActivitySeconds, 10),
+		strconv.FormatInt(v.PausesSeconds, 10),
+		strconv.FormatInt(v.GapSeconds, 10),
+	}
+}
+
+// writeTimeline writes timeline in machine readable format, csv contains records only.
+func writeTimeline(
+	format OutputFormat,
+	timelineComponent *timeline.Component,
+	issues *issuecache.Component,
+	model *timeline.Timeline,
+) error {
+	view, err := newTimelineView(timelineComponent, issues, model)
+	if err != nil {
+		return err
+	}
+	return writeOutput(os.Stdout, format, view, view.rows())
+}
+
+// rows of timeline for csv, there is a row for every record.
+func (v *TimelineView) rows() [][]string {
+	res := [][]string{recordHeader}
+	for _, r := range v.Records {
+		res = append(res, r.row())
+	}
+	return res
+}
+
+const (
+	StateEmpty   = "empty"
+	StateRunning = "running"
+	StatePaused  = "paused"
+	StateStopped = "stopped"
+)
+
+// StatusView is an output of status.
+type StatusView struct {
+	State  string      `json:"state" yaml:"state"`
+	Record *RecordView `json:"record,omitempty" yaml:"record,omitempty"`
+	// PauseSeconds is a duration of the current pause of paused record.
+	PauseSeconds int64 `json:"pauseSeconds" yaml:"pauseSeconds"`
+	// IdleSeconds is a time since finish of the last record if there is no running record.
+	IdleSeconds int64 `json:"idleSeconds" yaml:"idleSeconds"`
+}
+
+var statusHeader = []string{"state", "issueKey", "issueSummary", "activitySeconds", "pauseSeconds", "idleSeconds"}
+
+func newStatusView(num int, m *timeline.Model) *StatusView {
+	if m == nil {
+		return &StatusView{State: StateEmpty}
+	}
+	v := &StatusView{State: StateRunning, Record: newRecordView(num, m, nil)}
+	switch {
+	case m.IsFinished():
+		v.State = StateStopped
+		v.IdleSeconds = seconds(time.Now().Sub(m.FinishTime))
+	case m.IsPaused():
+		v.State = StatePaused
+		v.PauseSeconds = seconds(m.Pauses[len(m.Pauses)-1].Duration())
+	}
+	return v
+}
+
+// rows of status for csv, there is a row of the last record.
+func (v *StatusView) rows() [][]string {
+	if v.Record == nil {
+		return [][]string{statusHeader, {v.State, "", "", "0", "0", "0"}}
+	}
+	return [][]string{statusHeader, {
+		v.State,
+		v.Record.IssueKey,
+		v.Record.IssueSummary,
+		strconv.FormatInt(v.Record.ActivitySeconds, 10),
+		strconv.FormatInt(v.PauseSeconds, 10),
+		strconv.FormatInt(v.IdleSeconds, 10),
+	}}
+}
+
+// ReportColumnView is a part of period of report, dates are inclusive.
+type ReportColumnView struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// ReportRowView is a duration of records with the same key by columns of report.
+type ReportRowView struct {
+	Key          string  `json:"key" yaml:"key"`
+	Summary      string  `json:"summary" yaml:"summary"`
+	Seconds      []int64 `json:"seconds" yaml:"seconds"`
+	TotalSeconds int64   `json:"totalSeconds" yaml:"totalSeconds"`
+}
+
+// ReportSectionView groups rows of report by dimension, totals are by columns.
+type ReportSectionView struct {
+	Name          string           `json:"name" yaml:"name"`
+	Rows          []*ReportRowView `json:"rows" yaml:"rows"`
+	TotalsSeconds []int64          `json:"totalsSeconds" yaml:"totalsSeconds"`
+	TotalSeconds  int64            `json:"totalSeconds" yaml:"totalSeconds"`
+}
+
+// ReportView is an output of report.
+type ReportView struct {
+	Period   string               `json:"period" yaml:"period"`
+	From     string               `json:"from" yaml:"from"`
+	To       string               `json:"to" yaml:"to"`
+	Columns  []*ReportColumnView  `json:"columns" yaml:"columns"`
+	Sections []*ReportSectionView `json:"sections" yaml:"sections"`
+}
+
+var reportHeader = []string{"section", "key", "summary", "from", "to", "seconds"}
+
+func newReportView(r *timeline.Report) *ReportView {
+	allSeconds := func(list []time.Duration) []int64 {
+		res := make([]int64, 0, len(list))
+		for _, d := range list {
+			res = append(res, seconds(d))
+		}
+		return res
+	}
+	v := &ReportView{
+		Period:   string(r.Period),
+		From:     r.From.Format(dateLayout),
+		To:       r.Until.AddDate(0, 0, -1).Format(dateLayout),
+		Columns:  make([]*ReportColumnView, 0, len(r.Columns)),
+		Sections: make([]*ReportSectionView, 0, len(r.Sections)),
+	}
+	for _, col := range r.Columns {
+		v.Columns = append(v.Columns, &ReportColumnView{
+			From: col.From.Format(dateLayout),
+			To:   col.Until.AddDate(0, 0, -1).Format(dateLayout),
+		})
+	}
+	for _, s := range r.Sections {
+		sv := &ReportSectionView{
+			Name:          s.Name,
+			Rows:          make([]*ReportRowView, 0, len(s.Rows)),
+			TotalsSeconds: allSeconds(s.Totals),
+			TotalSeconds:  seconds(s.Total),
+		}
+		for _, row := range s.Rows {
+			sv.Rows = append(sv.Rows, &ReportRowView{
+				Key:          row.Key,
+				Summary:      row.Summary,
+				Seconds:      allSeconds(row.Durations),
+				TotalSeconds: seconds(row.Total),
+			})
+		}
+		v.Sections = append(v.Sections, sv)
+	}
+	return v
+}
+
+// rows of report for csv, there is a row for every cell of report.
+func (v *ReportView) rows() [][]string {
+	res := [][]string{reportHeader}
+	for _, s := range v.Sections {
+		for _, row := range s.Rows {
+			for i, col := range v.Columns {
+				res = append(res, []string{
+					s.Name, row.Key, row.Summary, col.From, col.To, strconv.FormatInt(row.Seconds[i], 10),
+				})
+			}
+		}
+	}
+	return res
+}
+
+var planHeader = []string{
+	"profile", "num", "records", "issueKey", "issueId", "started", "durationSeconds", "timeSpentSeconds",
+	"comment", "skipReason",
+}
+
+// planRows returns rows of plan of publishing for csv, there is a row for every item of plan.
+func planRows(plan *timeline.Plan) [][]string {
+	res := [][]string{planHeader}
+	for _, item := range plan.Items {
+		records := make([]string, 0, len(item.Records))
+		for _, num := range item.Records {
+			records = append(records, strconv.Itoa(num))
+		}
+		res = append(res, []string{
+			item.Profile,
+			strconv.Itoa(item.Num),
+			strings.Join(records, ","),
+			item.IssueKey,
+			item.IssueID,
+			item.Started.Format(time.RFC3339),
+			strconv.Itoa(item.DurationSeconds),
+			strconv.Itoa(item.TimeSpentSeconds),
+			item.Comment,
+			item.SkipReason,
+		})
+	}
+	return res
+}
@@ -0,0 +1,138 @@
+package action
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/timeline"
+)
+
+var update = flag.Bool("update", false, "update golden files of outputs")
+
+// assertGolden compares output of view in every machine readable format with golden file 'testdata/<name>.<format>'.
+func assertGolden(t *testing.T, name string, view interface{}, rows [][]string) {
+	for _, format := range []OutputFormat{OutputJSON, OutputYAML, OutputCSV} {
+		var buf bytes.Buffer
+		require.NoError(t, writeOutput(&buf, format, view, rows))
+
+		path := filepath.Join("testdata", name+"."+string(format))
+		if *update {
+			require.NoError(t, ioutil.WriteFile(path, buf.Bytes(), 0644))
+		}
+		expected, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), buf.String(), path)
+	}
+}
+
+var testStart = time.Date(2019, 7, 1, 10, 0, 0, 0, time.UTC)
+
+func testRecords() []*timeline.Model {
+	return []*timeline.Model{
+		{
+			Finished:    true,
+			StartTime:   testStart,
+			FinishTime:  testStart.Add(time.Hour),
+			Description: "Review",
+			Issue:       &timeline.Issue{Key: "A-1", Summary: "Task", Project: "A", Epic: "A-10"},
+			Tag:         "review",
+			Pauses:      []*timeline.Pause{{StartTime: testStart.Add(10 * time.Minute), FinishTime: testStart.Add(20 * time.Minute)}},
+			WorklogID:   "100",
+			Profile:     "work",
+		},
+		{
+			Finished:   true,
+			StartTime:  testStart.Add(90 * time.Minute),
+			FinishTime: testStart.Add(2 * time.Hour),
+			Issue:      &timeline.Issue{Key: "B-2", Summary: "Bug, \"urgent\"", Project: "B"},
+		},
+	}
+}
+
+func TestTimelineView_Golden(t *testing.T) {
+	records := testRecords()
+	view := &TimelineView{
+		Records: []*RecordView{newRecordView(0, records[0], nil), newRecordView(1, records[1], records[0])},
+		Tasks: []*TaskView{
+			{IssueKey: "A-1", Summary: "Task", Status: "Done", ActivitySeconds: 3000, PublishSeconds: 3600},
+			{IssueKey: "B-2", Summary: "Bug, \"urgent\"", Status: "Open", ActivitySeconds: 1800, PublishSeconds: 1800},
+		},
+		ActivitySeconds: 4800,
+		PublishSeconds:  5400,
+	}
+
+	assertGolden(t, "timeline", view, view.rows())
+}
+
+func TestStatusView_Golden(t *testing.T) {
+	running := testRecords()[0]
+	running.Finished = false
+	running.Pauses = append(running.Pauses, &timeline.Pause{StartTime: testStart.Add(30 * time.Minute)})
+	view := newStatusView(3, running)
+	// durations of the running record depend on now
+	view.Record.ActivitySeconds = 1200
+	view.Record.PausesSeconds = 900
+	view.PauseSeconds = 300
+
+	assert.Equal(t, StatePaused, view.State)
+	assertGolden(t, "status", view, view.rows())
+	empty := newStatusView(0, nil)
+	assertGolden(t, "status_empty", empty, empty.rows())
+}
+
+func TestReportView_Golden(t *testing.T) {
+	day := 24 * time.Hour
+	view := newReportView(&timeline.Report{
+		Period: timeline.PeriodWeek,
+		From:   testStart,
+		Until:  testStart.AddDate(0, 0, 2),
+		Columns: []*timeline.ReportColumn{
+			{From: testStart, Until: testStart.Add(day)},
+			{From: testStart.Add(day), Until: testStart.Add(2 * day)},
+		},
+		Sections: []*timeline.ReportSection{{
+			Name: "Issue",
+			Rows: []*timeline.ReportRow{
+				{Key: "A-1", Summary: "Task", Durations: []time.Duration{time.Hour, 0}, Total: time.Hour},
+				{Key: "B-2", Summary: "Bug", Durations: []time.Duration{0, 30 * time.Minute}, Total: 30 * time.Minute},
+			},
+			Totals: []time.Duration{time.Hour, 30 * time.Minute},
+			Total:  90 * time.Minute,
+		}},
+	})
+
+	assertGolden(t, "report", view, view.rows())
+}
+
+func TestPlan_Golden(t *testing.T) {
+	plan := &timeline.Plan{Items: []*timeline.PlanItem{
+		{
+			Profile:          "work",
+			Num:              0,
+			Records:          []int{0, 2},
+			IssueKey:         "A-1",
+			IssueID:          "10001",
+			Started:          testStart,
+			DurationSeconds:  3000,
+			TimeSpentSeconds: 3600,
+			Comment:          "review: Review",
+		},
+		{
+			Num:             1,
+			Records:         []int{1},
+			IssueKey:        "B-2",
+			Started:         testStart.Add(90 * time.Minute),
+			DurationSeconds: 30,
+			SkipReason:      timeline.SkipReasonShort,
+		},
+	}}
+
+	assertGolden(t, "plan", plan, planRows(plan))
+}
@@ -0,0 +1,37 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+
+	"github.com/andrskom/jwa-console/pkg/timeline"
+)
+
+func Pause(
+	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		model, err := timelineComponent.Pause()
+		if err != nil {
+			return err
+		}
+		fmt.Printf(`Pause task %s %s
+`, model.Issue.Key, model.Issue.Summary)
+		return nil
+	}
+}
+
+func Resume(
+	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		model, err := timelineComponent.Resume()
+		if err != nil {
+			return err
+		}
+		fmt.Printf(`Resume task %s %s
+`, model.Issue.Key, model.Issue.Summary)
+		return nil
+	}
+}
@@ -0,0 +1,49 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli"
+
+	"github.com/andrskom/jwa-console/pkg/profile"
+)
+
+func ProfileList(
+	profiles *profile.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		model, err := profiles.Get()
+		if err != nil {
+			return err
+		}
+		current, err := profiles.Current()
+		if err != nil {
+			return err
+		}
+		for _, name := range append([]string{profile.Default}, model.List...) {
+			if name == current {
+				activityColor.Printf("* %s\n", name)
+				continue
+			}
+			fmt.Printf("  %s\n", name)
+		}
+		return nil
+	}
+}
+
+func ProfileUse(
+	profiles *profile.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		name := c.Args().First()
+		if name == "" {
+			return errors.New("u must set name of profile as last arg")
+		}
+		if err := profiles.Use(name); err != nil {
+			return err
+		}
+		fmt.Printf("Use profile %s\n", name)
+		return nil
+	}
+}
@@ -1,8 +1,15 @@
 package action
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gosuri/uitable"
 	"github.com/urfave/cli"
 
 	"github.com/andrskom/jwa-console/pkg/timeline"
@@ -10,12 +17,150 @@ import (
 
 func Publish(
 	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return publish(timelineComponent, os.Stdin, os.Stdout, os.Stderr)
+}
+
+// publish writes plan to out, prompt and progress are written to errOut in json, yaml and csv formats,
+// so out contains only the plan which can be parsed.
+func publish(
+	timelineComponent *timeline.Component,
+	in io.Reader,
+	out io.Writer,
+	errOut io.Writer,
 ) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		if err := timelineComponent.Publish(); err != nil {
+		filter, err := getPublishFilter(c)
+		if err != nil {
+			return err
+		}
+		running, err := timeline.ParseRunningPolicy(c.String("running"))
+		if err != nil {
+			return err
+		}
+		opts := timeline.PublishOpts{
+			Filter:    filter,
+			Running:   running,
+			Aggregate: c.Bool("aggregate"),
+		}
+		plan, err := timelineComponent.Plan(opts)
+		if err != nil {
+			return err
+		}
+		info := out
+		if format := getOutputFormat(c); format != OutputText {
+			if err := writeOutput(out, format, plan, planRows(plan)); err != nil {
+				return err
+			}
+			info = errOut
+		} else {
+			fmt.Fprintln(out, drawPlan(plan))
+		}
+		if c.Bool("dry-run") {
+			return nil
+		}
+		if len(plan.Sendable()) == 0 {
+			warnColor.Fprintln(info, `Nothing to send`)
+			return nil
+		}
+		if !c.Bool("y") {
+			ok, err := confirm(in, info, fmt.Sprintf("Send %d worklogs?", len(plan.Sendable())))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+		}
+
+		if err := timelineComponent.Publish(opts); err != nil {
 			return err
 		}
-		fmt.Println(`Worklog sent`)
+		fmt.Fprintln(info, `Worklog sent`)
 		return nil
 	}
 }
+
+const dateLayout = "2006-01-02"
+
+func getPublishFilter(c *cli.Context) (*timeline.PublishFilter, error) {
+	filter := &timeline.PublishFilter{
+		Issues: c.StringSlice("issue"),
+		Tags:   c.StringSlice("tag"),
+	}
+	if records := c.String("records"); len(records) > 0 {
+		rng, err := parseRecordRange(records)
+		if err != nil {
+			return nil, err
+		}
+		filter.Records = rng
+	}
+	if len(c.String("from")) > 0 {
+		from, err := time.ParseInLocation(dateLayout, c.String("from"), time.Local)
+		if err != nil {
+			return nil, err
+		}
+		filter.From = from
+	}
+	if len(c.String("until")) > 0 {
+		until, err := time.ParseInLocation(dateLayout, c.String("until"), time.Local)
+		if err != nil {
+			return nil, err
+		}
+		filter.Until = until.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
+
+// parseRecordRange parses range in format 'N-M' or 'N'.
+func parseRecordRange(val string) (*timeline.RecordRange, error) {
+	parts := strings.SplitN(val, "-", 2)
+	from, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("bad range of records '%s': %w", val, err)
+	}
+	to := from
+	if len(parts) == 2 {
+		to, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("bad range of records '%s': %w", val, err)
+		}
+	}
+	if to < from {
+		return nil, fmt.Errorf("bad range of records '%s'", val)
+	}
+	return &timeline.RecordRange{From: from, To: to}, nil
+}
+
+func drawPlan(plan *timeline.Plan) string {
+	table := uitable.New()
+	table.MaxColWidth = 50
+	table.AddRow("RECORDS", "ISSUE", "STARTED", "DURATION", "TIME SPENT", "SECONDS", "COMMENT", "SKIPPED")
+	for _, item := range plan.Items {
+		records := make([]string, 0, len(item.Records))
+		for _, num := range item.Records {
+			records = append(records, strconv.Itoa(num))
+		}
+		table.AddRow(
+			strings.Join(records, ","),
+			item.IssueKey,
+			item.Started.Format(time.RFC822),
+			(time.Duration(item.DurationSeconds) * time.Second).String(),
+			(time.Duration(item.TimeSpentSeconds) * time.Second).String(),
+			item.TimeSpentSeconds,
+			item.Comment,
+			item.SkipReason,
+		)
+	}
+	return table.String()
+}
+
+func confirm(in io.Reader, out io.Writer, question string) (bool, error) {
+	fmt.Fprintf(out, "%s [y/N]: ", question)
+	text, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	text = strings.ToLower(strings.TrimSpace(text))
+	return text == "y" || text == "yes", nil
+}
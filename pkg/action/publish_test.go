@@ -0,0 +1,82 @@
+package action
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+
+	"github.com/andrskom/jwa-console/pkg/config"
+	"github.com/andrskom/jwa-console/pkg/internal/testjira"
+	"github.com/andrskom/jwa-console/pkg/internal/testutil"
+	"github.com/andrskom/jwa-console/pkg/profile"
+	"github.com/andrskom/jwa-console/pkg/timeline"
+)
+
+func getTestTimeline(t *testing.T, j *testjira.Server) *timeline.Component {
+	db := testutil.NewDB(t)
+	profiles := profile.NewComponent(db)
+	cfg := config.NewComponent(db, profiles)
+	require.NoError(t, cfg.Init())
+	c := timeline.NewComponent(db, testjira.NewFactory(t, db, profiles, j), cfg, profiles, nil)
+	require.NoError(t, c.Init())
+
+	return c
+}
+
+// runPublish runs publish command with args, input of prompt is read from in.
+func runPublish(t *testing.T, c *timeline.Component, in string, args ...string) (string, string) {
+	var out, errOut bytes.Buffer
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{cli.StringFlag{Name: "output", Value: string(OutputText)}}
+	app.Commands = []cli.Command{{
+		Name: "publish",
+		Flags: []cli.Flag{
+			cli.BoolFlag{Name: "dry-run"},
+			cli.BoolFlag{Name: "y"},
+			cli.StringFlag{Name: "running", Value: string(timeline.RunningKeep)},
+		},
+		Action: publish(c, strings.NewReader(in), &out, &errOut),
+	}}
+	require.NoError(t, app.Run(append([]string{"jwac"}, args...)))
+
+	return out.String(), errOut.String()
+}
+
+func TestPublish_MachineOutput_Golden(t *testing.T) {
+	for _, format := range []OutputFormat{OutputJSON, OutputYAML, OutputCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			j := &testjira.Server{Limit: -1}
+			c := getTestTimeline(t, j)
+			for i, key := range []string{"A-1", "B-2"} {
+				start := testStart.Add(time.Duration(i) * time.Hour)
+				_, err := c.Add(&timeline.Model{
+					Finished:   true,
+					StartTime:  start,
+					FinishTime: start.Add(30 * time.Minute),
+					Issue:      &timeline.Issue{Key: key, ID: "1000" + key[2:]},
+				})
+				require.NoError(t, err)
+			}
+
+			out, errOut := runPublish(t, c, "y\n", "--output", string(format), "publish")
+
+			path := filepath.Join("testdata", "publish."+string(format))
+			if *update {
+				require.NoError(t, ioutil.WriteFile(path, []byte(out), 0644))
+			}
+			expected, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), out, path)
+			assert.Contains(t, errOut, "Send 2 worklogs? [y/N]: ")
+			assert.Contains(t, errOut, "Worklog sent")
+			assert.Len(t, j.Worklogs, 2)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"github.com/andrskom/jwa-console/pkg/timeline"
+)
+
+func Remove(
+	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		num, err := getRecordNum(c, 0)
+		if err != nil {
+			return err
+		}
+		if err := timelineComponent.Remove(num); err != nil {
+			return err
+		}
+		fmt.Printf("Record %d removed\n", num)
+		return nil
+	}
+}
+
+func Split(
+	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		num, err := getRecordNum(c, 0)
+		if err != nil {
+			return err
+		}
+		if len(c.String("at")) == 0 {
+			return errors.New("u must set time of split with --at")
+		}
+		at, err := time.ParseInLocation(timeLayout, c.String("at"), time.Local)
+		if err != nil {
+			return err
+		}
+		var task *string
+		if len(c.String("task")) > 0 {
+			task = new(string)
+			*task = c.String("task")
+		}
+		if err := timelineComponent.Split(num, at, task); err != nil {
+			return err
+		}
+		fmt.Printf("Record %d split to %d and %d\n", num, num, num+1)
+		return nil
+	}
+}
+
+func Merge(
+	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		num, err := getRecordNum(c, 0)
+		if err != nil {
+			return err
+		}
+		nextNum, err := getRecordNum(c, 1)
+		if err != nil {
+			return err
+		}
+		if err := timelineComponent.Merge(num, nextNum); err != nil {
+			return err
+		}
+		fmt.Printf("Records %d and %d merged\n", num, nextNum)
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package action
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/urfave/cli"
+
+	"github.com/andrskom/jwa-console/pkg/timeline"
+)
+
+func Report(
+	timelineComponent *timeline.Component,
+) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		period := timeline.PeriodDay
+		if len(c.Args().First()) > 0 {
+			p, err := timeline.ParseReportPeriod(c.Args().First())
+			if err != nil {
+				return err
+			}
+			period = p
+		}
+		date := time.Now()
+		if len(c.String("date")) > 0 {
+			d, err := time.ParseInLocation(dateLayout, c.String("date"), time.Local)
+			if err != nil {
+				return err
+			}
+			date = d
+		}
+
+		report, err := timelineComponent.Report(period, date)
+		if err != nil {
+			return err
+		}
+		if format := getOutputFormat(c); format != OutputText {
+			view := newReportView(report)
+			return writeOutput(os.Stdout, format, view, view.rows())
+		}
+		fmt.Println(drawReport(report))
+		return nil
+	}
+}
+
+func drawReport(r *timeline.Report) string {
+	res := activityColor.Sprintf(
+		"Report of %s %s - %s\n",
+		r.Period,
+		r.From.Format(dateLayout),
+		r.Until.AddDate(0, 0, -1).Format(dateLayout),
+	)
+	for _, s := range r.Sections {
+		table := uitable.New()
+		table.MaxColWidth = 50
+		header := []interface{}{s.Name, ""}
+		for _, col := range r.Columns {
+			header = append(header, drawColumn(r.Period, col))
+		}
+		table.AddRow(append(header, "Total")...)
+		for _, row := range s.Rows {
+			cells := []interface{}{row.Key, row.Summary}
+			for _, d := range row.Durations {
+				cells = append(cells, drawReportDuration(d))
+			}
+			table.AddRow(append(cells, drawReportDuration(row.Total))...)
+		}
+		totals := []interface{}{"Total", ""}
+		for _, d := range s.Totals {
+			totals = append(totals, drawReportDuration(d))
+		}
+		table.AddRow(append(totals, drawReportDuration(s.Total))...)
+		for i := 2; i < len(r.Columns)+3; i++ {
+			table.RightAlign(i)
+		}
+		res += "\n" + table.String() + "\n"
+	}
+	return res
+}
+
+func drawColumn(period timeline.ReportPeriod, col *timeline.ReportColumn) string {
+	if period == timeline.PeriodMonth {
+		return col.From.Format("02") + "-" + col.Until.AddDate(0, 0, -1).Format("02")
+	}
+	return col.From.Format("Mon 02")
+}
+
+func drawReportDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Minute).String()
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gosuri/uitable"
 	"github.com/urfave/cli"
 
+	"github.com/andrskom/jwa-console/pkg/issuecache"
 	"github.com/andrskom/jwa-console/pkg/timeline"
 )
 
@@ -18,64 +19,106 @@ var warnColor = color.New(color.FgYellow)
 
 func Show(
 	timelineComponent *timeline.Component,
+	issues *issuecache.Component,
 ) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
 		model, err := timelineComponent.Get()
 		if err != nil {
 			return err
 		}
-		if len(model.List) == 0 {
-			warnColor.Println(`Nothing`)
+		if format := getOutputFormat(c); format != OutputText {
+			return writeTimeline(format, timelineComponent, issues, model)
 		}
-		var prevTask *timeline.Model
-		allDuration := time.Duration(0)
-		for i, task := range model.List {
-			if task.IsFinished() {
-				allDuration += task.Duration()
-			} else {
-				allDuration += time.Now().Sub(task.StartTime)
-			}
-			if prevTask != nil {
-				fmt.Print(drawRest(task, prevTask))
-			}
-			fmt.Printf("%2d %s", i, drawModel(task))
-			prevTask = task
+		return drawTimeline(timelineComponent, issues, model)
+	}
+}
 
+// drawTimeline prints records of timeline with durations by tasks.
+func drawTimeline(timelineComponent *timeline.Component, issues *issuecache.Component, model *timeline.Timeline) error {
+	if len(model.List) == 0 {
+		warnColor.Println(`Nothing`)
+	}
+	var prevTask *timeline.Model
+	allDuration := time.Duration(0)
+	for i, task := range model.List {
+		if task.IsFinished() {
+			allDuration += task.Duration()
+		} else {
+			allDuration += task.ActivityDuration()
 		}
-
-		table := uitable.New()
-		table.RightAlign(2)
-		for key, data := range model.GetDurationsByTasks() {
-			table.AddRow(key, data.Summary, data.Duration.String())
+		if prevTask != nil {
+			fmt.Print(drawRest(task, prevTask))
 		}
-		fmt.Println("\n" + table.String())
+		fmt.Printf("%2d %s", i, drawModel(task))
+		prevTask = task
+
+	}
 
-		fmt.Printf(
-			"\n%s %s\n",
-			activityColor.Sprint("Sum of activity:"),
-			getDuration(allDuration, activityColor),
-		)
+	rules, err := timelineComponent.RoundingFor(model)
+	if err != nil {
+		return err
+	}
+	table := uitable.New()
+	table.RightAlign(3)
+	table.RightAlign(4)
+	allRounded := time.Duration(0)
+	for key, data := range model.GetDurationsByTasks(rules) {
+		table.AddRow(key, data.Summary, getCachedStatus(issues, data.Profile, key), data.Duration.String(), data.Rounded.String())
+		allRounded += data.Rounded
+	}
+	fmt.Println("\n" + table.String())
+
+	fmt.Printf(
+		"\n%s %s\n",
+		activityColor.Sprint("Sum of activity:"),
+		getDuration(allDuration, activityColor),
+	)
+	fmt.Printf(
+		"%s %s\n",
+		activityColor.Sprint("Sum to publish:"),
+		getDuration(allRounded, activityColor),
+	)
 
-		return nil
+	return nil
+}
+
+// getCachedStatus returns status of issue from cache, jira isn't requested.
+func getCachedStatus(issues *issuecache.Component, profileName string, key string) string {
+	issue, err := issues.Lookup(profileName, key)
+	if err != nil || issue == nil {
+		return ""
 	}
+	return issue.Status
 }
 
 func drawModel(model *timeline.Model) string {
 	res := activityColor.Sprintf(`%s Start [%s] <%s> 
    %s
-`, model.StartTime.Format(time.RFC822), model.Issue.Key, model.Tag, model.Issue.Fields.Summary)
+`, model.StartTime.Format(time.RFC822), model.Issue.Key, model.Tag, model.Issue.Summary)
 	res += activityColor.Sprintf(`   + %s
 `, model.Description)
 
-	var interval time.Duration
-	if model.IsFinished() {
-		interval = model.Duration() / (time.Hour / 2)
-	} else {
-		interval = time.Now().Sub(model.StartTime) / (time.Hour / 2)
+	activityStart := model.StartTime
+	for _, pause := range model.Pauses {
+		res += drawInterval(pause.StartTime.Sub(activityStart), activityColor)
+		res += doNothingColor.Sprintf(`   %s Pause
+`, pause.StartTime.Format(time.RFC822))
+		res += drawInterval(pause.Duration(), doNothingColor)
+		if !pause.IsFinished() {
+			res += doNothingColor.Sprint(`   Paused `)
+			res += getDuration(pause.Duration(), doNothingColor) + "\n"
+			break
+		}
+		res += doNothingColor.Sprintf(`   %s Resume, pause duration: `, pause.FinishTime.Format(time.RFC822))
+		res += getDuration(pause.Duration(), doNothingColor) + "\n"
+		activityStart = pause.FinishTime
 	}
-	for interval > 0 {
-		interval--
-		res += activityColor.Sprintln("   |")
+	if !model.IsPaused() {
+		activityFinish := time.Now()
+		if model.IsFinished() {
+			activityFinish = model.FinishTime
+		}
+		res += drawInterval(activityFinish.Sub(activityStart), activityColor)
 	}
 
 	if model.IsFinished() {
@@ -92,13 +135,19 @@ func drawRest(model *timeline.Model, prevModel *timeline.Model) string {
 	res := doNothingColor.Sprintf(`   %s Do nothing
 `, prevModel.FinishTime.Format(time.RFC822))
 	dur := model.StartTime.Sub(prevModel.FinishTime)
+	res += drawInterval(dur, doNothingColor)
+	res += doNothingColor.Sprintf(`   %s Duration: `, model.StartTime.Format(time.RFC822))
+	res += getDuration(dur, doNothingColor) + "\n"
+	return res
+}
+
+func drawInterval(dur time.Duration, intervalColor *color.Color) string {
+	res := ""
 	interval := dur / (time.Hour / 2)
 	for interval > 0 {
 		interval--
-		res += doNothingColor.Sprintln("   |")
+		res += intervalColor.Sprintln("   |")
 	}
-	res += doNothingColor.Sprintf(`   %s Duration: `, model.StartTime.Format(time.RFC822))
-	res += getDuration(dur, doNothingColor) + "\n"
 	return res
 }
 
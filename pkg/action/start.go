@@ -34,6 +34,19 @@ func Start(
 			opts.UsePrevDescription = true
 		}
 
+		if c.Bool("refresh") {
+			if opts == nil {
+				opts = new(timeline.StartOpts)
+			}
+			opts.Refresh = true
+		}
+		if c.Bool("no-transition") {
+			if opts == nil {
+				opts = new(timeline.StartOpts)
+			}
+			opts.NoTransition = true
+		}
+
 		model, err := timelineComponent.BuildModel(taskID, opts)
 		if err != nil {
 			return err
@@ -42,13 +55,13 @@ func Start(
 			return err
 		}
 
-		model, err = timelineComponent.Start(model)
+		model, err = timelineComponent.Start(model, opts)
 		if err != nil {
 			return err
 		}
 
 		fmt.Printf(`Start task %s %s
-`, model.Issue.Key, model.Issue.Fields.Summary)
+`, model.Issue.Key, model.Issue.Summary)
 		return nil
 	}
 }
@@ -2,6 +2,7 @@ package action
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/urfave/cli"
@@ -13,6 +14,9 @@ func Status(
 	timelineComponent *timeline.Component,
 ) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
+		if format := getOutputFormat(c); format != OutputText {
+			return writeStatus(format, timelineComponent)
+		}
 		model, err := timelineComponent.GetCurrent()
 		if err != nil {
 			if err == timeline.ErrTimelineEmpty {
@@ -24,15 +28,36 @@ func Status(
 			doNothinDuration := time.Now().Sub(model.FinishTime)
 			fmt.Printf(`Last task: %s %s
 Do nothing: %s
-`, model.Issue.Key, model.Issue.Fields.Summary, doNothinDuration.String())
+`, model.Issue.Key, model.Issue.Summary, doNothinDuration.String())
+			return nil
+		}
+
+		if model.IsPaused() {
+			pause := model.Pauses[len(model.Pauses)-1]
+			fmt.Printf(`Paused task: %s %s
+Activity: %s
+Pause: %s
+`, model.Issue.Key, model.Issue.Summary, model.ActivityDuration().String(), pause.Duration().String())
 			return nil
 		}
 
-		taskDuration := time.Now().Sub(model.StartTime)
 		fmt.Printf(`Current task: %s %s
 Activity: %s
-`, model.Issue.Key, model.Issue.Fields.Summary, taskDuration.String())
+`, model.Issue.Key, model.Issue.Summary, model.ActivityDuration().String())
 
 		return nil
 	}
 }
+
+// writeStatus writes status in machine readable format, empty timeline isn't an error.
+func writeStatus(format OutputFormat, timelineComponent *timeline.Component) error {
+	model, err := timelineComponent.Get()
+	if err != nil {
+		return err
+	}
+	view := newStatusView(0, nil)
+	if num := len(model.List) - 1; num >= 0 {
+		view = newStatusView(num, model.List[num])
+	}
+	return writeOutput(os.Stdout, format, view, view.rows())
+}
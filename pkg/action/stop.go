@@ -17,7 +17,7 @@ func Stop(
 			return err
 		}
 		fmt.Printf(`Stop task %s %s
-`, model.Issue.Key, model.Issue.Fields.Summary)
+`, model.Issue.Key, model.Issue.Summary)
 		return nil
 	}
 }
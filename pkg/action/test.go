@@ -8,7 +8,7 @@ import (
 
 func Test() func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		log.Printf("%#v", )
+		log.Printf("%#v", c.Args())
 		return nil
 	}
 }
@@ -1,18 +1,30 @@
 package config
 
 import (
-	"encoding/json"
 	"errors"
-	"os"
 	"strings"
+	"time"
 
-	"github.com/andrskom/jwa-console/pkg/storage/file"
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/migration"
+	"github.com/andrskom/jwa-console/pkg/profile"
+	"github.com/andrskom/jwa-console/pkg/rounding"
 )
 
+// Version is the current version of stored config.
+const Version = 1
+
 type Model struct {
+	Version            int      `json:"version"`
 	Tags               []string `json:"tags"`
 	StatusesForStart   []string `json:"statusesForStart"`
 	AutoChangeStatusTo string   `json:"autoChangeStatusTo"`
+	RoundStep          string   `json:"roundStep"`
+	RoundMode          string   `json:"roundMode"`
+	MinDuration        string   `json:"minDuration"`
+	RoundByTags        string   `json:"roundByTags"`
+	IssueCacheTTL      string   `json:"issueCacheTTL"`
+	EpicLinkField      string   `json:"epicLinkField"`
 }
 
 func (m *Model) Set(key string, val string) error {
@@ -23,6 +35,33 @@ func (m *Model) Set(key string, val string) error {
 		m.StatusesForStart = strings.Split(val, ",")
 	case "autoChangeStatusTo":
 		m.AutoChangeStatusTo = val
+	case "roundStep":
+		if _, err := time.ParseDuration(val); err != nil {
+			return err
+		}
+		m.RoundStep = val
+	case "roundMode":
+		if _, err := rounding.ParseMode(val); err != nil {
+			return err
+		}
+		m.RoundMode = val
+	case "minDuration":
+		if _, err := time.ParseDuration(val); err != nil {
+			return err
+		}
+		m.MinDuration = val
+	case "roundByTags":
+		if _, err := rounding.ParseByTags(val); err != nil {
+			return err
+		}
+		m.RoundByTags = val
+	case "issueCacheTTL":
+		if _, err := time.ParseDuration(val); err != nil {
+			return err
+		}
+		m.IssueCacheTTL = val
+	case "epicLinkField":
+		m.EpicLinkField = val
 	default:
 		return errors.New("unexpected key of config field")
 	}
@@ -34,54 +73,120 @@ func (m *Model) AsMap() map[string]string {
 		"tags":               strings.Join(m.Tags, ","),
 		"statusesForStart":   strings.Join(m.StatusesForStart, ","),
 		"autoChangeStatusTo": m.AutoChangeStatusTo,
+		"roundStep":          m.RoundStep,
+		"roundMode":          m.RoundMode,
+		"minDuration":        m.MinDuration,
+		"roundByTags":        m.RoundByTags,
+		"issueCacheTTL":      m.IssueCacheTTL,
+		"epicLinkField":      m.EpicLinkField,
+	}
+}
+
+// Rounding builds rules of worklogs rounding.
+func (m *Model) Rounding() (*rounding.Rules, error) {
+	rules := rounding.Default()
+	if len(m.RoundStep) > 0 {
+		step, err := time.ParseDuration(m.RoundStep)
+		if err != nil {
+			return nil, err
+		}
+		rules.Default.Step = step
+	}
+	mode, err := rounding.ParseMode(m.RoundMode)
+	if err != nil {
+		return nil, err
+	}
+	rules.Default.Mode = mode
+	if len(m.MinDuration) > 0 {
+		if rules.MinDuration, err = time.ParseDuration(m.MinDuration); err != nil {
+			return nil, err
+		}
 	}
+	if rules.ByTag, err = rounding.ParseByTags(m.RoundByTags); err != nil {
+		return nil, err
+	}
+	return rules, nil
 }
 
 type Component struct {
-	db   *file.DB
-	file string
+	db       *filedb.JSON
+	table    string
+	profiles *profile.Component
 }
 
-func NewComponent(db *file.DB) *Component {
-	return &Component{db: db, file: "config.json"}
+func NewComponent(db *filedb.JSON, profiles *profile.Component) *Component {
+	return &Component{db: db, table: "config", profiles: profiles}
 }
 
+// Init creates config of the current profile if it doesn't exist.
 func (c *Component) Init() error {
-	_, err := c.db.ReadData(c.file)
-	if err != nil && !os.IsNotExist(err) {
+	table, err := c.currentTable()
+	if err != nil {
 		return err
 	}
-	if err == nil {
-		return nil
-	}
-
-	bytes, err := json.Marshal(Model{Tags: make([]string, 0), StatusesForStart: make([]string, 0)})
-	if err != nil {
+	has, err := c.db.Has(table)
+	if err != nil || has {
 		return err
 	}
 
-	return c.db.WriteData(c.file, bytes)
+	return c.db.Set(table, Model{Version: Version, Tags: make([]string, 0), StatusesForStart: make([]string, 0)})
 }
 
+// GetCfg returns config of the current profile.
 func (c *Component) GetCfg() (*Model, error) {
-	bytes, err := c.db.ReadData(c.file)
+	name, err := c.profiles.Current()
 	if err != nil {
 		return nil, err
 	}
+	return c.GetCfgFor(name)
+}
 
+func (c *Component) GetCfgFor(name string) (*Model, error) {
 	var cfg Model
-	if err := json.Unmarshal(bytes, &cfg); err != nil {
+	if err := c.db.Get(profile.TableName(c.table, name), &cfg); err != nil {
 		return nil, err
 	}
 
 	return &cfg, nil
 }
 
+// Save config of the current profile.
 func (c *Component) Save(m *Model) error {
-	bytes, err := json.Marshal(m)
+	table, err := c.currentTable()
 	if err != nil {
 		return err
 	}
+	m.Version = Version
 
-	return c.db.WriteData(c.file, bytes)
+	return c.db.Set(table, m)
+}
+
+// Update changes config of the current profile under lock of db.
+func (c *Component) Update(fn func(m *Model) error) error {
+	if err := c.db.Lock(); err != nil {
+		return err
+	}
+	defer c.db.Unlock()
+
+	m, err := c.GetCfg()
+	if err != nil {
+		return err
+	}
+	if err := fn(m); err != nil {
+		return err
+	}
+	return c.Save(m)
+}
+
+// Document returns versioned document of config.
+func (c *Component) Document() *migration.Document {
+	return &migration.Document{Table: c.table, VersionField: "version", Version: Version, PerProfile: true}
+}
+
+func (c *Component) currentTable() (string, error) {
+	name, err := c.profiles.Current()
+	if err != nil {
+		return "", err
+	}
+	return profile.TableName(c.table, name), nil
 }
@@ -2,32 +2,109 @@ package creds
 
 import (
 	"encoding/json"
+	"fmt"
 
-	"github.com/andrskom/jwa-console/pkg/storage/file"
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/migration"
+	"github.com/andrskom/jwa-console/pkg/profile"
 )
 
 type Component struct {
-	db   *file.DB
-	file string
+	db            *filedb.JSON
+	table         string
+	profiles      *profile.Component
+	passphrase    func() (string, error)
+	newPassphrase func() (string, error)
+	cached        *string
 }
 
-func New(db *file.DB) *Component {
-	return &Component{db: db, file: "auth.json"}
+// New creates component which asks passphrase of credentials via PromptPassphrase,
+// passphrase for the first encryption is asked with confirmation via PromptNewPassphrase.
+func New(db *filedb.JSON, profiles *profile.Component) *Component {
+	c := NewWithPassphrase(db, profiles, PromptPassphrase)
+	c.newPassphrase = PromptNewPassphrase
+	return c
 }
 
+// NewWithPassphrase creates component which gets passphrase of credentials via func, for example in tests.
+func NewWithPassphrase(db *filedb.JSON, profiles *profile.Component, passphrase func() (string, error)) *Component {
+	return &Component{db: db, table: "auth", profiles: profiles, passphrase: passphrase, newPassphrase: passphrase}
+}
+
+// Save credentials of the current profile.
 func (s *Component) Save(m *Model) error {
+	name, err := s.profiles.Current()
+	if err != nil {
+		return err
+	}
+	return s.SaveFor(name, m)
+}
+
+// SaveFor encrypts credentials of profile by passphrase and writes them readable only for owner.
+// Passphrase is asked with confirmation if profile has no encrypted credentials,
+// otherwise it must decrypt the saved credentials.
+func (s *Component) SaveFor(name string, m *Model) error {
 	data, err := json.Marshal(m)
 	if err != nil {
 		return err
 	}
-	return s.db.WriteData(s.file, data)
+	saved, err := s.getSealed(name)
+	if err != nil {
+		return err
+	}
+	var passphrase string
+	if saved == nil {
+		passphrase, err = s.getNewPassphrase()
+	} else {
+		passphrase, err = s.getPassphrase()
+		if err == nil {
+			_, err = open(saved, passphrase)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	data, err = seal(data, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Lock(); err != nil {
+		return err
+	}
+	defer s.db.Unlock()
+
+	return s.db.SetWithPerm(profile.TableName(s.table, name), json.RawMessage(data), 0600)
 }
 
+// Get credentials of the current profile.
 func (s *Component) Get() (*Model, error) {
-	data, err := s.db.ReadData(s.file)
+	name, err := s.profiles.Current()
+	if err != nil {
+		return nil, err
+	}
+	return s.GetFor(name)
+}
+
+// GetFor decrypts credentials of profile.
+// Plaintext credentials of old versions are encrypted by migration of Document before any command.
+func (s *Component) GetFor(name string) (*Model, error) {
+	var envelope sealed
+	if err := s.db.Get(profile.TableName(s.table, name), &envelope); err != nil {
+		return nil, err
+	}
+	if !envelope.isSealed() {
+		return nil, fmt.Errorf("credentials of profile %s aren't encrypted, they must be migrated", name)
+	}
+
+	passphrase, err := s.getPassphrase()
 	if err != nil {
 		return nil, err
 	}
+	data, err := open(&envelope, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
 	var res Model
 	if err := json.Unmarshal(data, &res); err != nil {
 		return nil, err
@@ -35,3 +112,70 @@ func (s *Component) Get() (*Model, error) {
 
 	return &res, nil
 }
+
+// getSealed returns encrypted credentials of profile, nil if profile has no encrypted credentials.
+func (s *Component) getSealed(name string) (*sealed, error) {
+	var envelope sealed
+	if err := s.db.Get(profile.TableName(s.table, name), &envelope); err != nil {
+		if filedb.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !envelope.isSealed() {
+		return nil, nil
+	}
+	return &envelope, nil
+}
+
+// Document returns versioned document of credentials, version of document is version of encryption envelope.
+func (s *Component) Document() *migration.Document {
+	return &migration.Document{
+		Table:        s.table,
+		VersionField: "Version",
+		Version:      sealedVersion,
+		PerProfile:   true,
+		Perm:         0600,
+		NoBackup:     true,
+		Migrations:   map[int]migration.Func{0: s.sealPlain},
+		// passphrase is asked before lock of migration, so prompt doesn't block other processes.
+		Prepare: func() error {
+			_, err := s.getNewPassphrase()
+			return err
+		},
+	}
+}
+
+// sealPlain encrypts plaintext credentials of old versions.
+func (s *Component) sealPlain(data []byte) ([]byte, error) {
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	passphrase, err := s.getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	return seal(data, passphrase)
+}
+
+func (s *Component) getPassphrase() (string, error) {
+	return s.askPassphrase(s.passphrase)
+}
+
+// getNewPassphrase asks passphrase for the first encryption of credentials.
+func (s *Component) getNewPassphrase() (string, error) {
+	return s.askPassphrase(s.newPassphrase)
+}
+
+func (s *Component) askPassphrase(ask func() (string, error)) (string, error) {
+	if s.cached != nil {
+		return *s.cached, nil
+	}
+	passphrase, err := ask()
+	if err != nil {
+		return "", err
+	}
+	s.cached = &passphrase
+	return passphrase, nil
+}
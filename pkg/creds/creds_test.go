@@ -0,0 +1,132 @@
+package creds
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/internal/testutil"
+	"github.com/andrskom/jwa-console/pkg/migration"
+	"github.com/andrskom/jwa-console/pkg/profile"
+)
+
+func getTestDB(t *testing.T) (*filedb.JSON, string) {
+	db := testutil.NewDB(t)
+	return db, db.DirPath()
+}
+
+func staticPassphrase(passphrase string) func() (string, error) {
+	return func() (string, error) {
+		return passphrase, nil
+	}
+}
+
+func TestComponent_SaveGet_Encrypted(t *testing.T) {
+	db, dir := getTestDB(t)
+	model := &Model{Method: AuthBasic, Username: "user", Password: "secret", Addr: "https://jira"}
+
+	require.NoError(t, NewWithPassphrase(db, profile.NewComponent(db), staticPassphrase("pass")).Save(model))
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "auth.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	info, err := os.Stat(filepath.Join(dir, "auth.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	res, err := NewWithPassphrase(db, profile.NewComponent(db), staticPassphrase("pass")).Get()
+	require.NoError(t, err)
+	assert.Equal(t, model, res)
+
+	_, err = NewWithPassphrase(db, profile.NewComponent(db), staticPassphrase("wrong")).Get()
+	assert.Equal(t, ErrWrongPassphrase, err)
+}
+
+func TestComponent_Get_Plaintext_Error(t *testing.T) {
+	db, _ := getTestDB(t)
+	data, err := json.Marshal(&Model{Username: "user", Password: "secret", Addr: "https://jira"})
+	require.NoError(t, err)
+	require.NoError(t, db.Set("auth", json.RawMessage(data)))
+
+	_, err = NewWithPassphrase(db, profile.NewComponent(db), staticPassphrase("pass")).Get()
+	assert.Error(t, err)
+}
+
+func TestComponent_Save_NewPassphraseConfirmedOnce(t *testing.T) {
+	db, _ := getTestDB(t)
+	profiles := profile.NewComponent(db)
+	asked := 0
+	first := NewWithPassphrase(db, profiles, staticPassphrase("pass"))
+	first.newPassphrase = func() (string, error) {
+		asked++
+		return "pass", nil
+	}
+	require.NoError(t, first.Save(&Model{Addr: "https://jira"}))
+	assert.Equal(t, 1, asked)
+
+	second := NewWithPassphrase(db, profiles, staticPassphrase("wrong"))
+	second.newPassphrase = func() (string, error) {
+		asked++
+		return "wrong", nil
+	}
+	assert.Equal(t, ErrWrongPassphrase, second.Save(&Model{Addr: "https://other"}))
+	assert.Equal(t, 1, asked, "passphrase of saved credentials isn't new")
+
+	res, err := first.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "https://jira", res.Addr)
+}
+
+func TestComponent_Document_SealsPlaintext(t *testing.T) {
+	db, dir := getTestDB(t)
+	model := &Model{Username: "user", Password: "secret", Addr: "https://jira"}
+	data, err := json.Marshal(model)
+	require.NoError(t, err)
+	require.NoError(t, db.Set("auth", json.RawMessage(data)))
+
+	profiles := profile.NewComponent(db)
+	component := NewWithPassphrase(db, profiles, staticPassphrase("wrong"))
+	component.newPassphrase = func() (string, error) {
+		locked, err := filedb.InitJSONWithDir(dir)
+		require.NoError(t, err)
+		require.NoError(t, locked.Lock(), "passphrase must be asked before lock")
+		require.NoError(t, locked.Unlock())
+		return "pass", nil
+	}
+	registry := migration.NewRegistry(db, profiles)
+	registry.Register(component.Document())
+	require.NoError(t, registry.Run())
+
+	info, err := os.Stat(filepath.Join(dir, "auth.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	for _, backup := range []string{"auth.json.v0.bak", "auth.json.bak"} {
+		_, err = os.Stat(filepath.Join(dir, backup))
+		assert.True(t, os.IsNotExist(err), backup)
+	}
+
+	res, err := component.Get()
+	require.NoError(t, err)
+	assert.Equal(t, model, res)
+}
+
+func TestPromptNewPassphrase_EmptyEnv(t *testing.T) {
+	old, ok := os.LookupEnv(PassphraseEnv)
+	require.NoError(t, os.Setenv(PassphraseEnv, ""))
+	defer func() {
+		if ok {
+			os.Setenv(PassphraseEnv, old)
+		} else {
+			os.Unsetenv(PassphraseEnv)
+		}
+	}()
+
+	_, err := PromptNewPassphrase()
+	assert.Equal(t, ErrEmptyPassphrase, err)
+}
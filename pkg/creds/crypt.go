@@ -0,0 +1,142 @@
+package creds
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// PassphraseEnv is an env var with passphrase of credentials, it's used instead of prompt.
+const PassphraseEnv = "JWAC_PASSPHRASE"
+
+const (
+	kdfScrypt     = "scrypt"
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	keyLen        = 32
+	saltLen       = 16
+	nonceLen      = 24
+	sealedVersion = 1
+)
+
+var ErrWrongPassphrase = errors.New("can't decrypt credentials, wrong passphrase")
+
+// ErrPassphraseMismatch is returned if confirmation of new passphrase differs.
+var ErrPassphraseMismatch = errors.New("passphrases don't match")
+var ErrEmptyPassphrase = errors.New("passphrase can't be empty")
+
+// sealed is an encrypted file of credentials.
+type sealed struct {
+	Version int
+	KDF     string
+	Salt    []byte
+	Nonce   []byte
+	Data    []byte
+}
+
+func (s *sealed) isSealed() bool {
+	return s.Version > 0 && len(s.Data) > 0
+}
+
+func deriveKey(passphrase string, salt []byte) (*[keyLen]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	var res [keyLen]byte
+	copy(res[:], key)
+	return &res, nil
+}
+
+func seal(data []byte, passphrase string) ([]byte, error) {
+	s := sealed{
+		Version: sealedVersion,
+		KDF:     kdfScrypt,
+		Salt:    make([]byte, saltLen),
+		Nonce:   make([]byte, nonceLen),
+	}
+	if _, err := io.ReadFull(rand.Reader, s.Salt); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, s.Nonce); err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, s.Salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceLen]byte
+	copy(nonce[:], s.Nonce)
+	s.Data = secretbox.Seal(nil, data, &nonce, key)
+
+	return json.Marshal(s)
+}
+
+func open(s *sealed, passphrase string) ([]byte, error) {
+	if s.KDF != kdfScrypt || len(s.Nonce) != nonceLen {
+		return nil, fmt.Errorf("unexpected format of encrypted credentials, version %d", s.Version)
+	}
+	key, err := deriveKey(passphrase, s.Salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceLen]byte
+	copy(nonce[:], s.Nonce)
+	data, ok := secretbox.Open(nil, s.Data, &nonce, key)
+	if !ok {
+		return nil, ErrWrongPassphrase
+	}
+	return data, nil
+}
+
+// PromptPassphrase gets passphrase from env or asks it in terminal.
+func PromptPassphrase() (string, error) {
+	if passphrase, ok := os.LookupEnv(PassphraseEnv); ok {
+		return passphrase, nil
+	}
+	return readPassphrase("Passphrase of credentials: ")
+}
+
+// PromptNewPassphrase gets passphrase from env or asks it in terminal twice for confirmation, empty passphrase is rejected.
+func PromptNewPassphrase() (string, error) {
+	if passphrase, ok := os.LookupEnv(PassphraseEnv); ok {
+		if len(passphrase) == 0 {
+			return "", ErrEmptyPassphrase
+		}
+		return passphrase, nil
+	}
+	passphrase, err := readPassphrase("New passphrase of credentials: ")
+	if err != nil {
+		return "", err
+	}
+	if len(passphrase) == 0 {
+		return "", ErrEmptyPassphrase
+	}
+	confirmation, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != confirmation {
+		return "", ErrPassphraseMismatch
+	}
+	return passphrase, nil
+}
+
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := terminal.ReadPassword(syscall.Stdin)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("can't read passphrase, use %s env for non interactive mode: %w", PassphraseEnv, err)
+	}
+	return string(passphrase), nil
+}
@@ -1,7 +1,45 @@
 package creds
 
+import (
+	"fmt"
+)
+
+// AuthMethod is a way of authentication in jira.
+type AuthMethod string
+
+const (
+	// AuthBasic is basic auth with username and password.
+	AuthBasic AuthMethod = "basic"
+	// AuthAPIToken is basic auth with email and api token, it's used by Atlassian Cloud.
+	AuthAPIToken AuthMethod = "api-token"
+	// AuthPAT is bearer auth with personal access token, it's used by Jira Server and Data Center.
+	AuthPAT AuthMethod = "pat"
+)
+
+func ParseAuthMethod(val string) (AuthMethod, error) {
+	switch m := AuthMethod(val); m {
+	case AuthBasic, AuthAPIToken, AuthPAT:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unexpected auth method '%s', expected one of: basic, api-token, pat", val)
+	}
+}
+
 type Model struct {
+	// Method is empty for creds saved before auth methods, it means basic auth.
+	Method AuthMethod
+	// Username is username for basic auth or email for api token.
 	Username string
+	// Password is password for basic auth or api token.
 	Password string
-	Addr     string
+	// Token is personal access token.
+	Token string
+	Addr  string
+}
+
+func (m *Model) GetMethod() AuthMethod {
+	if len(m.Method) == 0 {
+		return AuthBasic
+	}
+	return m.Method
 }
@@ -8,12 +8,16 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 )
 
 const (
 	defaultDBDir     = ".jwac"
 	defaultExtension = ".json"
+	backupExtension  = ".bak"
+	defaultPerm      = 0644
 )
 
 // ErrUnexpectedRuneInTableName is err for checking validating status outside.
@@ -51,14 +55,25 @@ type tableNameValidator interface {
 	Validate(tableName string) error
 }
 
+// IsNotExist checks if err is returned for table which doesn't exist.
+func IsNotExist(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
+
 // JSON is a simple file db.
 // Store data in json format.
+// Writes are atomic and keep the previous version of table as backup, reads fall back to backup if table is broken.
 type JSON struct {
 	mu                 sync.Mutex
 	serializer         func(obj interface{}) ([]byte, error)
 	deserializer       func(data []byte, obj interface{}) error
 	dirPath            string
 	tableNameValidator tableNameValidator
+
+	lockMu      sync.Mutex
+	lockTimeout time.Duration
+	lockFile    *os.File
+	locks       int
 }
 
 // NewJSON is the func for configuring db.
@@ -73,6 +88,7 @@ func NewJSON(
 		deserializer:       deserializer,
 		dirPath:            dirPath,
 		tableNameValidator: tableNameValidator,
+		lockTimeout:        defaultLockTimeout,
 	}
 }
 
@@ -83,9 +99,12 @@ func InitJSON() (*JSON, error) {
 		return nil, fmt.Errorf("json db initing try to read homedir for current user, %w", err)
 	}
 
-	dirPath := filepath.Join(usr.HomeDir, defaultDBDir)
+	return InitJSONWithDir(filepath.Join(usr.HomeDir, defaultDBDir))
+}
 
-	_, err = os.Stat(dirPath)
+// InitJSONWithDir init db with default settings and components in dir.
+func InitJSONWithDir(dirPath string) (*JSON, error) {
+	_, err := os.Stat(dirPath)
 	if os.IsNotExist(err) {
 		if err := os.MkdirAll(dirPath, 0755); err != nil {
 			return nil, fmt.Errorf("json db creating dir for db, %w", err)
@@ -101,14 +120,37 @@ func InitJSON() (*JSON, error) {
 		NewTableNameValidator(
 			'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n',
 			'o', 'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z',
-			'_',
+			'0', '1', '2', '3', '4', '5', '6', '7', '8', '9',
+			'_', '-', '.',
 		),
 	), nil
 }
 
+// DirPath returns dir of db.
+func (j *JSON) DirPath() string {
+	return j.dirPath
+}
+
 // Get fills objects if it's possible.
+// If table is broken or missing, for example after crash during write, it's filled from backup of the previous version.
 func (j *JSON) Get(tableName string, object interface{}) error {
-	data, err := ioutil.ReadFile(filepath.Join(j.dirPath, tableName+defaultExtension))
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	path := j.tablePath(tableName)
+	err := j.read(path, object)
+	if err == nil {
+		return nil
+	}
+	if backupErr := j.read(path+backupExtension, object); backupErr != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (j *JSON) read(path string, object interface{}) error {
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("json db reading data from file, %w", err)
 	}
@@ -122,8 +164,15 @@ func (j *JSON) Get(tableName string, object interface{}) error {
 
 // Set data to table if it's possible.
 func (j *JSON) Set(tableName string, object interface{}) error {
-	if err := j.CreateTableIfNotExists(tableName); err != nil {
-		return fmt.Errorf("creating file for table if not exists, %w", err)
+	return j.SetWithPerm(tableName, object, defaultPerm)
+}
+
+// SetWithPerm sets data to table with permission of file.
+// Data is written to temp file and renamed to the table, so the table isn't truncated by crash.
+// The previous version of the table is kept as backup.
+func (j *JSON) SetWithPerm(tableName string, object interface{}, perm os.FileMode) error {
+	if err := j.tableNameValidator.Validate(tableName); err != nil {
+		return fmt.Errorf("validatting table name %s error, %w", tableName, err)
 	}
 
 	data, err := j.serializer(object)
@@ -131,13 +180,133 @@ func (j *JSON) Set(tableName string, object interface{}) error {
 		return fmt.Errorf("json db serializeng, %w", err)
 	}
 
-	if err := ioutil.WriteFile(filepath.Join(j.dirPath, tableName+defaultExtension), data, 0644); err != nil {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.write(j.tablePath(tableName), data, perm); err != nil {
 		return fmt.Errorf("json db writing data, %w", err)
 	}
 
 	return nil
 }
 
+func (j *JSON) write(path string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(j.dirPath, filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeSync(tmp, data, perm); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if err := os.Rename(path, path+backupExtension); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	return syncDir(j.dirPath)
+}
+
+func writeSync(f *os.File, data []byte, perm os.FileMode) error {
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// Has checks if table exists.
+func (j *JSON) Has(tableName string) (bool, error) {
+	_, err := os.Stat(j.tablePath(tableName))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("getting status of file, %w", err)
+	}
+
+	return true, nil
+}
+
+// Tables returns sorted names of tables which start with prefix.
+func (j *JSON) Tables(prefix string) ([]string, error) {
+	files, err := ioutil.ReadDir(j.dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading dir of db, %w", err)
+	}
+
+	res := make([]string, 0)
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, defaultExtension) {
+			continue
+		}
+		res = append(res, strings.TrimSuffix(name, defaultExtension))
+	}
+
+	return res, nil
+}
+
+// RemoveBackup removes backup of the previous version of table, for example if it contains secrets.
+func (j *JSON) RemoveBackup(tableName string) error {
+	if err := os.Remove(j.tablePath(tableName) + backupExtension); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing backup of table %s, %w", tableName, err)
+	}
+
+	return nil
+}
+
+// Rename renames table with its backup, table with new name is replaced.
+func (j *JSON) Rename(tableName string, newTableName string) error {
+	if err := j.tableNameValidator.Validate(newTableName); err != nil {
+		return fmt.Errorf("validatting table name %s error, %w", newTableName, err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	from, to := j.tablePath(tableName), j.tablePath(newTableName)
+	if err := os.Rename(from+backupExtension, to+backupExtension); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("renaming backup of table %s, %w", tableName, err)
+	}
+	if err := os.Rename(from, to); err != nil {
+		return fmt.Errorf("renaming table %s, %w", tableName, err)
+	}
+
+	return syncDir(j.dirPath)
+}
+
+func (j *JSON) tablePath(tableName string) string {
+	return filepath.Join(j.dirPath, tableName+defaultExtension)
+}
+
 // CreateTableIfNotExists helps to make an easy idempotent set operation.
 // If table is already exists, returns no error.
 // It table isn't exists, tries to create it.
@@ -146,7 +315,7 @@ func (j *JSON) CreateTableIfNotExists(tableName string) error {
 		return fmt.Errorf("validatting table name %s error, %w", tableName, err)
 	}
 
-	tablePath := filepath.Join(j.dirPath, tableName+defaultExtension)
+	tablePath := j.tablePath(tableName)
 
 	_, err := os.Stat(tablePath)
 	if os.IsNotExist(err) {
@@ -15,6 +15,17 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// getTestDB creates db in temp dir, which is removed after test.
+func getTestDB(t *testing.T) *JSON {
+	tmpDir, err := ioutil.TempDir("", "jwac")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	db, err := InitJSONWithDir(tmpDir)
+	require.NoError(t, err)
+	return db
+}
+
 func getTestSerializer(
 	t *testing.T,
 	expectedObj interface{},
@@ -156,3 +167,73 @@ func TestJSON_Get_PositiveCase_NoErr(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "a", data)
 }
+
+func TestJSON_SetWithPerm_KeepsBackup(t *testing.T) {
+	db := getTestDB(t)
+
+	require.NoError(t, db.SetWithPerm("a", 1, 0600))
+	require.NoError(t, db.SetWithPerm("a", 2, 0600))
+
+	var data int
+	require.NoError(t, db.Get("a", &data))
+	assert.Equal(t, 2, data)
+	backup, err := ioutil.ReadFile(filepath.Join(db.DirPath(), "a.json.bak"))
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(backup))
+
+	info, err := os.Stat(filepath.Join(db.DirPath(), "a.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	files, err := ioutil.ReadDir(db.DirPath())
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "temp files must be removed")
+}
+
+func TestJSON_Get_BrokenTable_FilledFromBackup(t *testing.T) {
+	db := getTestDB(t)
+
+	require.NoError(t, db.Set("a", 1))
+	require.NoError(t, db.Set("a", 2))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(db.DirPath(), "a.json"), []byte("{"), 0644))
+
+	var data int
+	require.NoError(t, db.Get("a", &data))
+	assert.Equal(t, 1, data)
+
+	require.NoError(t, os.Remove(filepath.Join(db.DirPath(), "a.json")))
+	data = 0
+	require.NoError(t, db.Get("a", &data))
+	assert.Equal(t, 1, data)
+
+	require.NoError(t, db.RemoveBackup("a"))
+	assert.True(t, IsNotExist(db.Get("a", &data)))
+}
+
+func TestJSON_Rename_WithBackup(t *testing.T) {
+	db := getTestDB(t)
+
+	require.NoError(t, db.Set("a", 1))
+	require.NoError(t, db.Set("a", 2))
+	require.NoError(t, db.Rename("a", "b.old"))
+
+	var data int
+	assert.True(t, IsNotExist(db.Get("a", &data)))
+	require.NoError(t, db.Get("b.old", &data))
+	assert.Equal(t, 2, data)
+	_, err := os.Stat(filepath.Join(db.DirPath(), "b.old.json.bak"))
+	assert.NoError(t, err)
+}
+
+func TestJSON_Tables(t *testing.T) {
+	db := getTestDB(t)
+
+	require.NoError(t, db.Set("a.2", 1))
+	require.NoError(t, db.Set("a.2", 2))
+	require.NoError(t, db.Set("a.1", 1))
+	require.NoError(t, db.Set("b", 1))
+
+	tables, err := db.Tables("a.")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.1", "a.2"}, tables)
+}
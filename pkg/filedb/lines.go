@@ -0,0 +1,116 @@
+package filedb
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const linesExtension = ".jsonl"
+
+// Append adds object as a line to the end of table of lines.
+// Torn last line, which is left by crash during append, is dropped before append.
+func (j *JSON) Append(tableName string, object interface{}) error {
+	if err := j.tableNameValidator.Validate(tableName); err != nil {
+		return fmt.Errorf("validatting table name %s error, %w", tableName, err)
+	}
+
+	data, err := j.serializer(object)
+	if err != nil {
+		return fmt.Errorf("json db serializeng, %w", err)
+	}
+	if bytes.IndexByte(data, '\n') >= 0 {
+		return fmt.Errorf("json db serialized object contains new line")
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.OpenFile(j.linesPath(tableName), os.O_CREATE|os.O_RDWR, defaultPerm)
+	if err != nil {
+		return fmt.Errorf("json db opening table of lines, %w", err)
+	}
+	if err := appendLine(f, data); err != nil {
+		f.Close()
+		return fmt.Errorf("json db appending line, %w", err)
+	}
+
+	return f.Close()
+}
+
+func appendLine(f *os.File, data []byte) error {
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	end := int64(bytes.LastIndexByte(content, '\n') + 1)
+	if end != int64(len(content)) {
+		if err := f.Truncate(end); err != nil {
+			return err
+		}
+	}
+	if _, err := f.WriteAt(append(data, '\n'), end); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// GetLines calls fn for every line of table of lines, fn deserializes line by decode.
+// Torn last line isn't passed to fn.
+func (j *JSON) GetLines(tableName string, fn func(decode func(object interface{}) error) error) error {
+	j.mu.Lock()
+	data, err := ioutil.ReadFile(j.linesPath(tableName))
+	j.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("json db reading data from file, %w", err)
+	}
+
+	lines := bytes.Split(data, []byte{'\n'})
+	// the last item is empty or a torn line
+	for i, line := range lines[:len(lines)-1] {
+		decode := func(object interface{}) error {
+			if err := j.deserializer(line, object); err != nil {
+				return fmt.Errorf("json db deserializing line %d, %w", i+1, err)
+			}
+			return nil
+		}
+		if err := fn(decode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetLines replaces table of lines by objects atomically.
+func (j *JSON) SetLines(tableName string, objects ...interface{}) error {
+	if err := j.tableNameValidator.Validate(tableName); err != nil {
+		return fmt.Errorf("validatting table name %s error, %w", tableName, err)
+	}
+
+	var buf bytes.Buffer
+	for _, object := range objects {
+		data, err := j.serializer(object)
+		if err != nil {
+			return fmt.Errorf("json db serializeng, %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.write(j.linesPath(tableName), buf.Bytes(), defaultPerm); err != nil {
+		return fmt.Errorf("json db writing data, %w", err)
+	}
+
+	return nil
+}
+
+func (j *JSON) linesPath(tableName string) string {
+	return filepath.Join(j.dirPath, tableName+linesExtension)
+}
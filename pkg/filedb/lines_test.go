@@ -0,0 +1,46 @@
+package filedb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestLines(t *testing.T, db *JSON, tableName string) []int {
+	res := make([]int, 0)
+	require.NoError(t, db.GetLines(tableName, func(decode func(object interface{}) error) error {
+		var v int
+		if err := decode(&v); err != nil {
+			return err
+		}
+		res = append(res, v)
+		return nil
+	}))
+	return res
+}
+
+func TestJSON_AppendGetLines(t *testing.T) {
+	db := getTestDB(t)
+
+	assert.True(t, IsNotExist(db.GetLines("a", nil)))
+
+	require.NoError(t, db.Append("a", 1))
+	require.NoError(t, db.Append("a", 2))
+	assert.Equal(t, []int{1, 2}, getTestLines(t, db, "a"))
+
+	f, err := os.OpenFile(filepath.Join(db.DirPath(), "a.jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("3")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, []int{1, 2}, getTestLines(t, db, "a"), "torn line must be skipped")
+
+	require.NoError(t, db.Append("a", 4))
+	assert.Equal(t, []int{1, 2, 4}, getTestLines(t, db, "a"))
+
+	require.NoError(t, db.SetLines("a", 5, 6))
+	assert.Equal(t, []int{5, 6}, getTestLines(t, db, "a"))
+}
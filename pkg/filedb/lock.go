@@ -0,0 +1,81 @@
+package filedb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const (
+	lockFileName       = "lock"
+	defaultLockTimeout = 5 * time.Second
+	lockPollInterval   = 50 * time.Millisecond
+)
+
+// ErrLocked is err of lock, which is held by another process longer than timeout.
+var ErrLocked = errors.New("another jwac is writing, try again later")
+
+// Lock takes exclusive advisory lock of db for read-modify-write sequence.
+// It waits for another process up to timeout, lock is reentrant within the process.
+func (j *JSON) Lock() error {
+	j.lockMu.Lock()
+	defer j.lockMu.Unlock()
+
+	if j.locks > 0 {
+		j.locks++
+		return nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(j.dirPath, lockFileName), os.O_CREATE|os.O_RDWR, defaultPerm)
+	if err != nil {
+		return fmt.Errorf("json db opening lock file, %w", err)
+	}
+
+	deadline := time.Now().Add(j.lockTimeout)
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if err != syscall.EWOULDBLOCK {
+			f.Close()
+			return fmt.Errorf("json db locking, %w", err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return ErrLocked
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	j.lockFile = f
+	j.locks = 1
+
+	return nil
+}
+
+// Unlock releases lock taken by Lock.
+func (j *JSON) Unlock() error {
+	j.lockMu.Lock()
+	defer j.lockMu.Unlock()
+
+	if j.locks == 0 {
+		return errors.New("json db isn't locked")
+	}
+	j.locks--
+	if j.locks > 0 {
+		return nil
+	}
+
+	f := j.lockFile
+	j.lockFile = nil
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_UN); err != nil {
+		f.Close()
+		return fmt.Errorf("json db unlocking, %w", err)
+	}
+
+	return f.Close()
+}
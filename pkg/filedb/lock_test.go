@@ -0,0 +1,28 @@
+package filedb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON_Lock(t *testing.T) {
+	db := getTestDB(t)
+	other, err := InitJSONWithDir(db.DirPath())
+	require.NoError(t, err)
+	other.lockTimeout = 100 * time.Millisecond
+
+	require.NoError(t, db.Lock())
+	require.NoError(t, db.Lock(), "lock must be reentrant")
+	assert.Equal(t, ErrLocked, other.Lock())
+
+	require.NoError(t, db.Unlock())
+	assert.Equal(t, ErrLocked, other.Lock())
+
+	require.NoError(t, db.Unlock())
+	require.NoError(t, other.Lock())
+	require.NoError(t, other.Unlock())
+	assert.Error(t, other.Unlock())
+}
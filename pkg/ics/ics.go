@@ -0,0 +1,83 @@
+package ics
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	timeLayout = "20060102T150405Z"
+	// lineLimit is a max length of content line in octets without line break.
+	lineLimit = 75
+	prodID    = "-//andrskom//jwa-console//EN"
+)
+
+// Event is a VEVENT of calendar.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+// Write writes calendar with events in iCalendar format, stamp is a time of creation of calendar.
+func Write(w io.Writer, events []*Event, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:" + prodID, "CALSCALE:GREGORIAN"}
+	for _, e := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escape(e.UID),
+			"DTSTAMP:"+formatTime(stamp),
+			"DTSTART:"+formatTime(e.Start),
+			"DTEND:"+formatTime(e.End),
+			"SUMMARY:"+escape(e.Summary),
+		)
+		if len(e.Description) > 0 {
+			lines = append(lines, "DESCRIPTION:"+escape(e.Description))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := bw.WriteString(fold(line)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escape escapes text value.
+func escape(val string) string {
+	return escaper.Replace(val)
+}
+
+// fold splits line to lines not longer than lineLimit octets, every next line starts with space.
+func fold(line string) string {
+	var b strings.Builder
+	limit := lineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = lineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
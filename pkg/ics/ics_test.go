@@ -0,0 +1,52 @@
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.FixedZone("MSK", 3*3600))
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, []*Event{{
+		UID:         "1@jwac",
+		Summary:     "A-1 Fix, test; done",
+		Description: "dev\nline",
+		Start:       start,
+		End:         start.Add(time.Hour),
+	}}, start))
+
+	assert.Equal(t, "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"PRODID:"+prodID+"\r\n"+
+		"CALSCALE:GREGORIAN\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"UID:1@jwac\r\n"+
+		"DTSTAMP:20190707T070000Z\r\n"+
+		"DTSTART:20190707T070000Z\r\n"+
+		"DTEND:20190707T080000Z\r\n"+
+		`SUMMARY:A-1 Fix\, test\; done`+"\r\n"+
+		`DESCRIPTION:dev\nline`+"\r\n"+
+		"END:VEVENT\r\n"+
+		"END:VCALENDAR\r\n", buf.String())
+}
+
+func TestFold(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("ж", 100)
+	folded := fold(line)
+
+	parts := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	require.True(t, len(parts) > 1)
+	for i, part := range parts {
+		assert.True(t, len(part) <= lineLimit, part)
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(part, " "))
+		}
+	}
+	assert.Equal(t, line, strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", ""))
+}
@@ -0,0 +1,110 @@
+// Package testjira contains a fake jira for tests of components which request jira.
+package testjira
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/creds"
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/jiraf"
+	"github.com/andrskom/jwa-console/pkg/profile"
+)
+
+// Passphrase of credentials saved by NewFactory.
+const Passphrase = "test"
+
+// Server is a fake jira, it fails worklogs after Limit of accepted ones, negative limit is unlimited.
+// Offline jira drops all connections, Dropping jira drops connections of failed worklogs.
+// Issues have Status, which is changed by transition to "In Progress" from any other status,
+// Payload is returned for issue instead of generated one if it's set.
+// Hook is called on every request, for example for changes of db by another command.
+type Server struct {
+	Worklogs      []string
+	Limit         int
+	Offline       bool
+	Dropping      bool
+	Status        string
+	Transitions   int
+	IssueRequests int
+	Payload       string
+	Hook          func(r *http.Request)
+}
+
+func (j *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if j.Hook != nil {
+		j.Hook(r)
+	}
+	if j.Offline {
+		dropConnection(w)
+		return
+	}
+	switch {
+	case r.URL.Path == "/rest/api/2/myself":
+		fmt.Fprint(w, `{"name":"user"}`)
+	case strings.HasSuffix(r.URL.Path, "/transitions") && r.Method == http.MethodGet:
+		if j.Status == "In Progress" {
+			fmt.Fprint(w, `{"transitions":[]}`)
+			return
+		}
+		fmt.Fprint(w, `{"transitions":[{"id":"11","to":{"name":"In Progress"}}]}`)
+	case strings.HasSuffix(r.URL.Path, "/transitions") && r.Method == http.MethodPost:
+		j.Transitions++
+		j.Status = "In Progress"
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/"):
+		j.IssueRequests++
+		if len(j.Payload) > 0 {
+			fmt.Fprint(w, j.Payload)
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/")
+		fmt.Fprintf(
+			w,
+			`{"id":"1","key":"%s","fields":{"summary":"Task","status":{"name":"%s"},"project":{"key":"%s"}}}`,
+			key,
+			j.Status,
+			strings.Split(key, "-")[0],
+		)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/worklog"):
+		if j.Limit >= 0 && len(j.Worklogs) >= j.Limit {
+			if j.Dropping {
+				dropConnection(w)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		j.Worklogs = append(j.Worklogs, r.URL.Path)
+		fmt.Fprintf(w, `{"id":"%d"}`, len(j.Worklogs))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// dropConnection closes connection without response, client gets transport error.
+func dropConnection(w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+// Start runs fake jira till the end of test and returns its address.
+func Start(t *testing.T, j *Server) string {
+	srv := httptest.NewServer(j)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// NewFactory runs fake jira and returns factory of clients with credentials of the current profile for it.
+func NewFactory(t *testing.T, db *filedb.JSON, profiles *profile.Component, j *Server) *jiraf.Factory {
+	credsComponent := creds.NewWithPassphrase(db, profiles, func() (string, error) { return Passphrase, nil })
+	require.NoError(t, credsComponent.Save(&creds.Model{Addr: Start(t, j)}))
+	return jiraf.NewFactory(credsComponent)
+}
@@ -0,0 +1,25 @@
+// Package testutil contains fixtures shared by tests of packages.
+package testutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/filedb"
+)
+
+// NewDB creates db in 'db' dir of temp dir, the temp dir is removed after test.
+// The parent of db dir can be used for other files of test, for example for data of old versions.
+func NewDB(t *testing.T) *filedb.JSON {
+	tmpDir, err := ioutil.TempDir("", "jwac")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	db, err := filedb.InitJSONWithDir(filepath.Join(tmpDir, "db"))
+	require.NoError(t, err)
+	return db
+}
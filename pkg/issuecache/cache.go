@@ -0,0 +1,217 @@
+package issuecache
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+
+	"github.com/andrskom/jwa-console/pkg/config"
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/jiraf"
+	"github.com/andrskom/jwa-console/pkg/migration"
+	"github.com/andrskom/jwa-console/pkg/profile"
+)
+
+// DefaultTTL is a time of life of cached issue if it isn't configured.
+const DefaultTTL = 24 * time.Hour
+
+// DefaultEpicLinkField is a custom field with key of epic if it isn't configured,
+// it's the epic link field of Jira Cloud.
+const DefaultEpicLinkField = "customfield_10014"
+
+// Issue is a cached short info about jira issue.
+type Issue struct {
+	Key       string
+	ID        string
+	Summary   string
+	Status    string
+	Project   string
+	Epic      string
+	FetchedAt time.Time
+}
+
+// FromJira makes short info about issue, key of epic is read from custom field epicLinkField.
+func FromJira(issue *jira.Issue, epicLinkField string) *Issue {
+	res := &Issue{
+		Key:       issue.Key,
+		ID:        issue.ID,
+		FetchedAt: time.Now(),
+	}
+	if issue.Fields == nil {
+		return res
+	}
+	res.Summary = issue.Fields.Summary
+	res.Project = issue.Fields.Project.Key
+	if issue.Fields.Status != nil {
+		res.Status = issue.Fields.Status.Name
+	}
+	if epic, ok := issue.Fields.Unknowns[epicLinkField].(string); ok {
+		res.Epic = epic
+	} else if issue.Fields.Epic != nil {
+		res.Epic = issue.Fields.Epic.Key
+	}
+	return res
+}
+
+func (i *Issue) IsExpired(ttl time.Duration) bool {
+	return time.Now().Sub(i.FetchedAt) > ttl
+}
+
+// Version is the current version of stored cache.
+const Version = 1
+
+// Table of cached issues by key.
+type Table struct {
+	Version int
+	Issues  map[string]*Issue
+}
+
+type Component struct {
+	db          *filedb.JSON
+	table       string
+	jiraFactory *jiraf.Factory
+	cfg         *config.Component
+}
+
+func NewComponent(db *filedb.JSON, jiraFactory *jiraf.Factory, cfg *config.Component) *Component {
+	return &Component{db: db, table: "issues", jiraFactory: jiraFactory, cfg: cfg}
+}
+
+// Get returns issue of profile from cache, expired or refreshed issue is fetched from jira.
+// If jira isn't available, expired issue from cache is returned.
+func (c *Component) Get(profileName string, key string, refresh bool) (*Issue, error) {
+	table, err := c.getTable(profileName)
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := c.getTTL(profileName)
+	if err != nil {
+		return nil, err
+	}
+	cached, ok := table.Issues[key]
+	if ok && !refresh && !cached.IsExpired(ttl) {
+		return cached, nil
+	}
+
+	issue, err := c.fetch(profileName, key)
+	if err != nil {
+		if ok {
+			log.Printf("Can't fetch issue %s, cached from %s is used: %s", key, cached.FetchedAt.Format(time.RFC822), err.Error())
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	table.Issues[issue.Key] = issue
+	if err := c.saveTable(profileName, table); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+// List returns cached issues of profile sorted by key.
+func (c *Component) List(profileName string) ([]*Issue, error) {
+	table, err := c.getTable(profileName)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Issue, 0, len(table.Issues))
+	for _, issue := range table.Issues {
+		res = append(res, issue)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Key < res[j].Key
+	})
+	return res, nil
+}
+
+// Lookup returns cached issue of profile without fetching, nil if it isn't cached.
+func (c *Component) Lookup(profileName string, key string) (*Issue, error) {
+	table, err := c.getTable(profileName)
+	if err != nil {
+		return nil, err
+	}
+	return table.Issues[key], nil
+}
+
+// SetStatus updates status of cached issue, for example after transition. Not cached issue is skipped.
+func (c *Component) SetStatus(profileName string, key string, status string) error {
+	table, err := c.getTable(profileName)
+	if err != nil {
+		return err
+	}
+	issue, ok := table.Issues[key]
+	if !ok {
+		return nil
+	}
+	issue.Status = status
+	return c.saveTable(profileName, table)
+}
+
+func (c *Component) fetch(profileName string, key string) (*Issue, error) {
+	client, err := c.jiraFactory.GetClientFor(profileName)
+	if err != nil {
+		return nil, err
+	}
+	epicLinkField, err := c.getEpicLinkField(profileName)
+	if err != nil {
+		return nil, err
+	}
+	issue, resp, err := client.Issue.Get(key, nil)
+	if err != nil {
+		if resp == nil {
+			return nil, fmt.Errorf("can't get jira issue %s: %w", key, err)
+		}
+		return nil, fmt.Errorf("unexpected jira response, while try to get issue %s: %s", key, resp.Status)
+	}
+	return FromJira(issue, epicLinkField), nil
+}
+
+func (c *Component) getEpicLinkField(profileName string) (string, error) {
+	cfg, err := c.cfg.GetCfgFor(profileName)
+	if err != nil {
+		return "", err
+	}
+	if len(cfg.EpicLinkField) == 0 {
+		return DefaultEpicLinkField, nil
+	}
+	return cfg.EpicLinkField, nil
+}
+
+func (c *Component) getTTL(profileName string) (time.Duration, error) {
+	cfg, err := c.cfg.GetCfgFor(profileName)
+	if err != nil {
+		return 0, err
+	}
+	if len(cfg.IssueCacheTTL) == 0 {
+		return DefaultTTL, nil
+	}
+	return time.ParseDuration(cfg.IssueCacheTTL)
+}
+
+func (c *Component) getTable(profileName string) (*Table, error) {
+	var res Table
+	if err := c.db.Get(profile.TableName(c.table, profileName), &res); err != nil {
+		if filedb.IsNotExist(err) {
+			return &Table{Issues: make(map[string]*Issue)}, nil
+		}
+		return nil, err
+	}
+	if res.Issues == nil {
+		res.Issues = make(map[string]*Issue)
+	}
+	return &res, nil
+}
+
+// Document returns versioned document of cache.
+func (c *Component) Document() *migration.Document {
+	return &migration.Document{Table: c.table, VersionField: "Version", Version: Version, PerProfile: true}
+}
+
+func (c *Component) saveTable(profileName string, t *Table) error {
+	t.Version = Version
+	return c.db.Set(profile.TableName(c.table, profileName), t)
+}
@@ -0,0 +1,104 @@
+package issuecache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/config"
+	"github.com/andrskom/jwa-console/pkg/internal/testjira"
+	"github.com/andrskom/jwa-console/pkg/internal/testutil"
+	"github.com/andrskom/jwa-console/pkg/profile"
+)
+
+func getTestComponent(t *testing.T, j *testjira.Server) *Component {
+	db := testutil.NewDB(t)
+	profiles := profile.NewComponent(db)
+	cfg := config.NewComponent(db, profiles)
+	require.NoError(t, cfg.Init())
+
+	return NewComponent(db, testjira.NewFactory(t, db, profiles, j), cfg)
+}
+
+func TestComponent_Get(t *testing.T) {
+	j := &testjira.Server{Status: "Open"}
+	c := getTestComponent(t, j)
+
+	issue, err := c.Get(profile.Default, "A-1", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Task", issue.Summary)
+	assert.Equal(t, "Open", issue.Status)
+	assert.Equal(t, "A", issue.Project)
+	assert.Equal(t, 1, j.IssueRequests)
+
+	_, err = c.Get(profile.Default, "A-1", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, j.IssueRequests, "cached")
+
+	j.Status = "Done"
+	issue, err = c.Get(profile.Default, "A-1", true)
+	require.NoError(t, err)
+	assert.Equal(t, "Done", issue.Status, "refreshed")
+	assert.Equal(t, 2, j.IssueRequests)
+
+	j.Offline = true
+	issue, err = c.Get(profile.Default, "A-1", true)
+	require.NoError(t, err)
+	assert.Equal(t, "Done", issue.Status, "offline")
+
+	_, err = c.Get(profile.Default, "A-2", false)
+	assert.Error(t, err, "offline and not cached")
+}
+
+func TestIssue_IsExpired(t *testing.T) {
+	issue := &Issue{FetchedAt: time.Now().Add(-2 * time.Hour)}
+	assert.True(t, issue.IsExpired(time.Hour))
+	assert.False(t, issue.IsExpired(DefaultTTL))
+}
+
+// issuePayload is a response of /rest/api/2/issue with epic link in custom fields and without agile epic field.
+const issuePayload = `{
+	"expand": "renderedFields,names,schema,operations,editmeta,changelog,versionedRepresentations",
+	"id": "10042",
+	"self": "https://jira.example.com/rest/api/2/issue/10042",
+	"key": "A-1",
+	"fields": {
+		"issuetype": {"id": "10001", "name": "Story", "subtask": false},
+		"project": {"id": "10000", "key": "A", "name": "Alpha"},
+		"summary": "Task",
+		"status": {"id": "3", "name": "In Progress", "statusCategory": {"id": 4, "key": "indeterminate"}},
+		"priority": {"id": "3", "name": "Medium"},
+		"labels": ["backend"],
+		"customfield_10014": "A-10",
+		"customfield_10100": "B-20",
+		"customfield_10016": 3.0,
+		"customfield_10020": null
+	}
+}`
+
+func TestComponent_Get_Epic(t *testing.T) {
+	j := &testjira.Server{Payload: issuePayload}
+	c := getTestComponent(t, j)
+
+	issue, err := c.Get(profile.Default, "A-1", false)
+	require.NoError(t, err)
+	assert.Equal(t, "A-10", issue.Epic)
+	assert.Equal(t, "In Progress", issue.Status)
+	assert.Equal(t, "A", issue.Project)
+
+	require.NoError(t, c.cfg.Update(func(m *config.Model) error {
+		return m.Set("epicLinkField", "customfield_10100")
+	}))
+	issue, err = c.Get(profile.Default, "A-1", true)
+	require.NoError(t, err)
+	assert.Equal(t, "B-20", issue.Epic, "configured field")
+
+	require.NoError(t, c.cfg.Update(func(m *config.Model) error {
+		return m.Set("epicLinkField", "customfield_10020")
+	}))
+	issue, err = c.Get(profile.Default, "A-1", true)
+	require.NoError(t, err)
+	assert.Empty(t, issue.Epic, "issue without epic")
+}
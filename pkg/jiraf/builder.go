@@ -1,6 +1,9 @@
 package jiraf
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/andygrunwald/go-jira"
 
 	"github.com/andrskom/jwa-console/pkg/creds"
@@ -14,6 +17,11 @@ func NewFactory(credsComponent *creds.Component) *Factory {
 	return &Factory{credsComponent: credsComponent}
 }
 
+func (b *Factory) GetCredsComponent() *creds.Component {
+	return b.credsComponent
+}
+
+// GetClient returns client of the current profile.
 func (b *Factory) GetClient() (*jira.Client, error) {
 	model, err := b.credsComponent.Get()
 	if err != nil {
@@ -22,11 +30,57 @@ func (b *Factory) GetClient() (*jira.Client, error) {
 	return BuildByCredsModel(model)
 }
 
+// GetClientFor returns client of profile.
+func (b *Factory) GetClientFor(profile string) (*jira.Client, error) {
+	model, err := b.credsComponent.GetFor(profile)
+	if err != nil {
+		return nil, err
+	}
+	return BuildByCredsModel(model)
+}
+
 func BuildByCredsModel(model *creds.Model) (*jira.Client, error) {
-	tp := jira.BasicAuthTransport{
-		Username: model.Username,
-		Password: model.Password,
+	switch model.GetMethod() {
+	case creds.AuthBasic, creds.AuthAPIToken:
+		tp := jira.BasicAuthTransport{
+			Username: model.Username,
+			Password: model.Password,
+		}
+		return jira.NewClient(tp.Client(), model.Addr)
+	case creds.AuthPAT:
+		tp := BearerAuthTransport{Token: model.Token}
+		return jira.NewClient(tp.Client(), model.Addr)
+	default:
+		return nil, fmt.Errorf("unexpected auth method '%s'", model.Method)
 	}
+}
+
+// BearerAuthTransport is an http.RoundTripper that authenticates all requests
+// using bearer token, for example personal access token of jira.
+type BearerAuthTransport struct {
+	Token string
 
-	return jira.NewClient(tp.Client(), model.Addr)
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *BearerAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req2 := req.Clone(req.Context())
+	req2.Header.Set("Authorization", "Bearer "+t.Token)
+	return t.transport().RoundTrip(req2)
+}
+
+// Client returns an *http.Client that makes requests that are authenticated
+// using bearer token.
+func (t *BearerAuthTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+func (t *BearerAuthTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
 }
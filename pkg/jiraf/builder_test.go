@@ -0,0 +1,41 @@
+package jiraf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/creds"
+)
+
+func TestBuildByCredsModel_AuthHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    creds.Model
+		expected string
+	}{
+		{"empty method is basic", creds.Model{Username: "u", Password: "p"}, "Basic dTpw"},
+		{"api token", creds.Model{Method: creds.AuthAPIToken, Username: "u", Password: "p"}, "Basic dTpw"},
+		{"pat", creds.Model{Method: creds.AuthPAT, Token: "t"}, "Bearer t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Get("Authorization")
+				w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			tt.model.Addr = srv.URL
+			client, err := BuildByCredsModel(&tt.model)
+			require.NoError(t, err)
+			_, _, err = client.User.GetSelf()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, header)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportDir moves json files of old versions from dir, for example '~/.jwarc', to tables of db.
+// Existing tables aren't overwritten. The dir is renamed with '.bak' suffix, so it's imported once.
+// Tables of documents without backup, for example plaintext credentials, are migrated to the current version
+// before writing and are removed from dir, so they aren't kept in the renamed dir.
+func (r *Registry) ImportDir(dir string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	tables := make([]os.FileInfo, 0, len(infos))
+	prepared := make(map[*Document]struct{})
+	for _, info := range infos {
+		if info.IsDir() || filepath.Ext(info.Name()) != ".json" {
+			continue
+		}
+		tables = append(tables, info)
+		// secret documents are prepared before lock, so prompt doesn't block other processes.
+		doc := r.secretDocument(strings.TrimSuffix(info.Name(), ".json"))
+		if _, ok := prepared[doc]; doc == nil || ok {
+			continue
+		}
+		if err := doc.prepare(); err != nil {
+			return err
+		}
+		prepared[doc] = struct{}{}
+	}
+
+	if err := r.db.Lock(); err != nil {
+		return err
+	}
+	defer r.db.Unlock()
+
+	for _, info := range tables {
+		table := strings.TrimSuffix(info.Name(), ".json")
+		if err := r.importTable(dir, table, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("importing %s: %w", info.Name(), err)
+		}
+	}
+
+	return os.Rename(dir, dir+".bak")
+}
+
+func (r *Registry) importTable(dir string, table string, perm os.FileMode) error {
+	path := filepath.Join(dir, table+".json")
+	secret := r.secretDocument(table)
+	has, err := r.db.Has(table)
+	if err != nil {
+		return err
+	}
+	if !has {
+		data, err := readFileWithBackup(path)
+		if err != nil {
+			return err
+		}
+		if secret != nil {
+			if data, _, err = secret.upgrade(data); err != nil {
+				return err
+			}
+			if err := r.write(secret, table, data); err != nil {
+				return err
+			}
+		} else if err := r.db.SetWithPerm(table, json.RawMessage(data), perm); err != nil {
+			return err
+		}
+	}
+	if secret == nil {
+		return nil
+	}
+
+	for _, p := range []string{path, path + ".bak"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// secretDocument returns document of table if it isn't backed up, nil otherwise.
+func (r *Registry) secretDocument(table string) *Document {
+	for _, doc := range r.docs {
+		if doc.NoBackup && doc.owns(table) {
+			return doc
+		}
+	}
+	return nil
+}
+
+// readFileWithBackup reads json file or its backup if file is broken.
+func readFileWithBackup(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if json.Valid(data) {
+		return data, nil
+	}
+	backup, err := ioutil.ReadFile(path + ".bak")
+	if err != nil || !json.Valid(backup) {
+		return nil, fmt.Errorf("file %s is broken", path)
+	}
+	return backup, nil
+}
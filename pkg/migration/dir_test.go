@@ -0,0 +1,46 @@
+package migration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportDir(t *testing.T) {
+	r, db := getTestRegistry(t)
+	r.Register(&Document{Table: "auth", VersionField: "v", Version: 2, PerProfile: true, NoBackup: true, Perm: 0600,
+		Migrations: map[int]Func{
+			1: func(data []byte) ([]byte, error) { return []byte(`{"sealed":true}`), nil },
+		}})
+	oldDir := filepath.Join(filepath.Dir(db.DirPath()), "old")
+	require.NoError(t, os.Mkdir(oldDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(oldDir, "init"), nil, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(oldDir, "timeline.json"), []byte(`{"List":[]}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(oldDir, "auth.client.json"), []byte(`{"v":`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(oldDir, "auth.client.json.bak"), []byte(`{"v":1}`), 0600))
+	require.NoError(t, db.Set("config", map[string]int{"v": 2}))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(oldDir, "config.json"), []byte(`{"v":1}`), 0644))
+
+	require.NoError(t, r.ImportDir(oldDir))
+
+	assert.Equal(t, `{"List":[]}`, getRaw(t, db, "timeline"))
+	assert.JSONEq(t, `{"sealed":true,"v":2}`, getRaw(t, db, "auth.client"))
+	assert.Equal(t, `{"v":2}`, getRaw(t, db, "config"), "existing table must not be overwritten")
+	info, err := os.Stat(filepath.Join(db.DirPath(), "auth.client.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = os.Stat(oldDir)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(oldDir + ".bak")
+	assert.NoError(t, err)
+	plain, err := filepath.Glob(filepath.Join(oldDir+".bak", "auth*"))
+	require.NoError(t, err)
+	assert.Empty(t, plain, "plaintext credentials must not survive import")
+
+	assert.NoError(t, r.ImportDir(oldDir), "dir is imported once")
+}
@@ -0,0 +1,233 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/profile"
+)
+
+// Func migrates document data to the next version.
+type Func func(data []byte) ([]byte, error)
+
+// Document describes versioned json document stored in table.
+type Document struct {
+	Table string
+	// Tables lists tables of document if their names aren't known in advance, Table isn't used then.
+	Tables func() ([]string, error)
+	// VersionField is a name of field of json object with version of document, missing field means zero version.
+	VersionField string
+	// Version is the current version of document.
+	Version int
+	// PerProfile documents are stored in table per profile.
+	PerProfile bool
+	// Perm of migrated table file, default is permission of filedb.JSON.Set.
+	Perm os.FileMode
+	// NoBackup disables backups of old document, for example for plaintext secrets.
+	NoBackup bool
+	// Migrations by version which they migrate from, every migration upgrades document to the next version.
+	Migrations map[int]Func
+	// Prepare is called before lock of db if document must be migrated, for example for prompt of passphrase.
+	Prepare func() error
+}
+
+// Registry migrates documents stored by old versions of jwac.
+type Registry struct {
+	db       *filedb.JSON
+	profiles *profile.Component
+	docs     []*Document
+}
+
+// NewRegistry creates registry with document of profiles, which is needed for finding tables of profiles.
+func NewRegistry(db *filedb.JSON, profiles *profile.Component) *Registry {
+	r := &Registry{db: db, profiles: profiles}
+	r.Register(&Document{Table: profiles.Table(), VersionField: "Version", Version: profile.Version})
+	return r
+}
+
+func (r *Registry) Register(docs ...*Document) {
+	r.docs = append(r.docs, docs...)
+}
+
+// Run migrates all registered documents to the current versions.
+// The old document is kept as backup table with version suffix, for example 'timeline.v0'.
+// Versions are checked without lock of db, so the lock is taken only if some document must be migrated.
+func (r *Registry) Run() error {
+	outdated := make([]*Document, 0)
+	err := r.eachTable(func(doc *Document, table string) error {
+		if len(outdated) > 0 && outdated[len(outdated)-1] == doc {
+			return nil
+		}
+		ok, err := r.isOutdated(doc, table)
+		if ok {
+			outdated = append(outdated, doc)
+		}
+		return err
+	})
+	if err != nil || len(outdated) == 0 {
+		return err
+	}
+	for _, doc := range outdated {
+		if err := doc.prepare(); err != nil {
+			return err
+		}
+	}
+
+	if err := r.db.Lock(); err != nil {
+		return err
+	}
+	defer r.db.Unlock()
+
+	return r.eachTable(func(doc *Document, table string) error {
+		if err := r.migrate(doc, table); err != nil {
+			return fmt.Errorf("migrating %s: %w", table, err)
+		}
+		return nil
+	})
+}
+
+// eachTable calls fn for every table of registered documents.
+func (r *Registry) eachTable(fn func(doc *Document, table string) error) error {
+	profiles, err := r.profiles.Get()
+	if err != nil {
+		return err
+	}
+	for _, doc := range r.docs {
+		tables := []string{doc.Table}
+		if doc.Tables != nil {
+			if tables, err = doc.Tables(); err != nil {
+				return err
+			}
+		}
+		if doc.PerProfile {
+			for _, name := range profiles.List {
+				tables = append(tables, profile.TableName(doc.Table, name))
+			}
+		}
+		for _, table := range tables {
+			if err := fn(doc, table); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isOutdated checks if table of document has an old version.
+func (r *Registry) isOutdated(doc *Document, table string) (bool, error) {
+	var data json.RawMessage
+	if err := r.db.Get(table, &data); err != nil {
+		if filedb.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	version, err := ReadVersion(data, doc.VersionField)
+	if err != nil {
+		return false, fmt.Errorf("reading version of %s: %w", table, err)
+	}
+	return version != doc.Version, nil
+}
+
+func (r *Registry) migrate(doc *Document, table string) error {
+	var data json.RawMessage
+	if err := r.db.Get(table, &data); err != nil {
+		if filedb.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	migrated, version, err := doc.upgrade(data)
+	if err != nil {
+		return err
+	}
+	if version == doc.Version {
+		return nil
+	}
+
+	if doc.NoBackup {
+		if err := r.write(doc, table, migrated); err != nil {
+			return err
+		}
+		return r.db.RemoveBackup(table)
+	}
+	if err := r.write(doc, table+".v"+strconv.Itoa(version), data); err != nil {
+		return err
+	}
+	return r.write(doc, table, migrated)
+}
+
+// write writes data of document to table with permission of document.
+func (r *Registry) write(doc *Document, table string, data []byte) error {
+	if doc.Perm != 0 {
+		return r.db.SetWithPerm(table, json.RawMessage(data), doc.Perm)
+	}
+	return r.db.Set(table, json.RawMessage(data))
+}
+
+// upgrade migrates data of document to the current version, returns the version which data had.
+func (doc *Document) upgrade(data []byte) ([]byte, int, error) {
+	version, err := ReadVersion(data, doc.VersionField)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version > doc.Version {
+		return nil, 0, fmt.Errorf("version %d is written by newer version of jwac, expected %d", version, doc.Version)
+	}
+
+	migrated := data
+	for v := version; v < doc.Version; v++ {
+		if fn, ok := doc.Migrations[v]; ok {
+			if migrated, err = fn(migrated); err != nil {
+				return nil, 0, fmt.Errorf("from version %d: %w", v, err)
+			}
+		}
+		if migrated, err = WriteVersion(migrated, doc.VersionField, v+1); err != nil {
+			return nil, 0, err
+		}
+	}
+	return migrated, version, nil
+}
+
+func (doc *Document) prepare() error {
+	if doc.Prepare == nil {
+		return nil
+	}
+	return doc.Prepare()
+}
+
+// owns checks if table is a table of document or of its profile.
+func (doc *Document) owns(table string) bool {
+	return table == doc.Table || (doc.PerProfile && strings.HasPrefix(table, doc.Table+"."))
+}
+
+// ReadVersion reads version of document from field, missing field means zero version.
+func ReadVersion(data []byte, field string) (int, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return 0, err
+	}
+	raw, ok := obj[field]
+	if !ok {
+		return 0, nil
+	}
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, fmt.Errorf("bad version of document: %w", err)
+	}
+	return version, nil
+}
+
+// WriteVersion sets version of document to field.
+func WriteVersion(data []byte, field string, version int) ([]byte, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	obj[field] = json.RawMessage(strconv.Itoa(version))
+	return json.Marshal(obj)
+}
@@ -0,0 +1,77 @@
+package migration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/internal/testutil"
+	"github.com/andrskom/jwa-console/pkg/profile"
+)
+
+func getTestRegistry(t *testing.T) (*Registry, *filedb.JSON) {
+	db := testutil.NewDB(t)
+	return NewRegistry(db, profile.NewComponent(db)), db
+}
+
+func getRaw(t *testing.T, db *filedb.JSON, table string) string {
+	var data json.RawMessage
+	require.NoError(t, db.Get(table, &data))
+	return string(data)
+}
+
+func TestRegistry_Run(t *testing.T) {
+	r, db := getTestRegistry(t)
+	profiles := profile.NewComponent(db)
+	require.NoError(t, profiles.Add("client"))
+	require.NoError(t, db.Set("doc", json.RawMessage(`{"a":1}`)))
+	require.NoError(t, db.Set("doc.client", json.RawMessage(`{"a":2,"v":1}`)))
+
+	r.Register(&Document{Table: "doc", VersionField: "v", Version: 2, PerProfile: true, Migrations: map[int]Func{
+		0: func(data []byte) ([]byte, error) { return []byte(`{"b":1}`), nil },
+		1: func(data []byte) ([]byte, error) { return []byte(`{"c":1}`), nil },
+	}})
+	require.NoError(t, r.Run())
+
+	assert.JSONEq(t, `{"c":1,"v":2}`, getRaw(t, db, "doc"))
+	assert.Equal(t, `{"a":1}`, getRaw(t, db, "doc.v0"))
+	assert.JSONEq(t, `{"c":1,"v":2}`, getRaw(t, db, "doc.client"))
+
+	require.NoError(t, r.Run())
+	assert.JSONEq(t, `{"c":1,"v":2}`, getRaw(t, db, "doc"))
+}
+
+func TestRegistry_Run_NewerVersion(t *testing.T) {
+	r, db := getTestRegistry(t)
+	require.NoError(t, db.Set("doc", json.RawMessage(`{"v":3}`)))
+	r.Register(&Document{Table: "doc", VersionField: "v", Version: 2})
+
+	assert.Error(t, r.Run())
+}
+
+func TestRegistry_Run_MissingTable(t *testing.T) {
+	r, _ := getTestRegistry(t)
+	r.Register(&Document{Table: "doc", VersionField: "v", Version: 2})
+
+	assert.NoError(t, r.Run())
+}
+
+func TestRegistry_Run_ListedTables(t *testing.T) {
+	r, db := getTestRegistry(t)
+	require.NoError(t, db.Set("doc.1", json.RawMessage(`{"a":1}`)))
+	require.NoError(t, db.Set("doc.2", json.RawMessage(`{"a":2,"v":1}`)))
+
+	r.Register(&Document{
+		Tables:       func() ([]string, error) { return db.Tables("doc.") },
+		VersionField: "v",
+		Version:      1,
+	})
+	require.NoError(t, r.Run())
+
+	assert.JSONEq(t, `{"a":1,"v":1}`, getRaw(t, db, "doc.1"))
+	assert.Equal(t, `{"a":1}`, getRaw(t, db, "doc.1.v0"))
+	assert.JSONEq(t, `{"a":2,"v":1}`, getRaw(t, db, "doc.2"))
+}
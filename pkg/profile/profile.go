@@ -0,0 +1,146 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/andrskom/jwa-console/pkg/filedb"
+)
+
+// Default is a profile which uses tables without profile suffix.
+const Default = "default"
+
+// Version is the current version of stored profiles.
+const Version = 1
+
+type Model struct {
+	Version int
+	Current string
+	List    []string
+}
+
+func (m *Model) Has(name string) bool {
+	if name == Default {
+		return true
+	}
+	for _, p := range m.List {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+type Component struct {
+	db       *filedb.JSON
+	table    string
+	override string
+}
+
+func NewComponent(db *filedb.JSON) *Component {
+	return &Component{db: db, table: "profiles"}
+}
+
+// SetOverride sets profile which is used instead of the current one in this run.
+func (c *Component) SetOverride(name string) error {
+	if err := Validate(name); err != nil {
+		return err
+	}
+	c.override = name
+	return nil
+}
+
+// Current returns overridden profile or the current one from settings.
+func (c *Component) Current() (string, error) {
+	if len(c.override) > 0 {
+		return c.override, nil
+	}
+	m, err := c.Get()
+	if err != nil {
+		return "", err
+	}
+	if len(m.Current) == 0 {
+		return Default, nil
+	}
+	return m.Current, nil
+}
+
+func (c *Component) Get() (*Model, error) {
+	var res Model
+	if err := c.db.Get(c.table, &res); err != nil {
+		if filedb.IsNotExist(err) {
+			return &Model{List: make([]string, 0)}, nil
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Add registers profile, it's idempotent.
+func (c *Component) Add(name string) error {
+	if err := Validate(name); err != nil {
+		return err
+	}
+	if err := c.db.Lock(); err != nil {
+		return err
+	}
+	defer c.db.Unlock()
+
+	m, err := c.Get()
+	if err != nil {
+		return err
+	}
+	if m.Has(name) {
+		return nil
+	}
+	m.List = append(m.List, name)
+	return c.save(m)
+}
+
+// Use makes profile current.
+func (c *Component) Use(name string) error {
+	if err := c.db.Lock(); err != nil {
+		return err
+	}
+	defer c.db.Unlock()
+
+	m, err := c.Get()
+	if err != nil {
+		return err
+	}
+	if !m.Has(name) {
+		return fmt.Errorf("profile '%s' not found, use 'jwac login --profile %s' for creating it", name, name)
+	}
+	m.Current = name
+	return c.save(m)
+}
+
+// Table returns name of table with profiles.
+func (c *Component) Table() string {
+	return c.table
+}
+
+func (c *Component) save(m *Model) error {
+	m.Version = Version
+	return c.db.Set(c.table, m)
+}
+
+// Validate name of profile, it's used as part of table name.
+func Validate(name string) error {
+	if len(name) == 0 {
+		return fmt.Errorf("name of profile is empty")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return fmt.Errorf("unexpected rune '%c' in name of profile, expected a-z, 0-9, '_' and '-'", r)
+		}
+	}
+	return nil
+}
+
+// TableName returns name of profile table, for example 'auth.client' for 'auth'.
+func TableName(table string, name string) string {
+	if len(name) == 0 || name == Default {
+		return table
+	}
+	return table + "." + name
+}
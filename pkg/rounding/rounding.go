@@ -0,0 +1,121 @@
+package rounding
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Mode of rounding.
+type Mode string
+
+const (
+	ModeNearest Mode = "nearest"
+	ModeUp      Mode = "up"
+	ModeDown    Mode = "down"
+)
+
+// DefaultMinDuration is a minimal duration of sent worklog if it isn't configured.
+const DefaultMinDuration = time.Minute
+
+func ParseMode(val string) (Mode, error) {
+	switch m := Mode(val); m {
+	case ModeNearest, ModeUp, ModeDown:
+		return m, nil
+	case "":
+		return ModeNearest, nil
+	default:
+		return "", fmt.Errorf("unexpected rounding mode '%s', expected one of: nearest, up, down", val)
+	}
+}
+
+// Rule rounds duration to step by mode, zero step means no rounding.
+type Rule struct {
+	Step time.Duration
+	Mode Mode
+}
+
+// ParseRule parses rule in format 'step/mode' or 'step', for example '15m/up'.
+func ParseRule(val string) (Rule, error) {
+	parts := strings.SplitN(val, "/", 2)
+	step, err := time.ParseDuration(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Rule{}, fmt.Errorf("bad rounding step '%s': %w", val, err)
+	}
+	if step < 0 {
+		return Rule{}, fmt.Errorf("rounding step '%s' must not be negative", val)
+	}
+	mode := ModeNearest
+	if len(parts) == 2 {
+		if mode, err = ParseMode(strings.TrimSpace(parts[1])); err != nil {
+			return Rule{}, err
+		}
+	}
+	return Rule{Step: step, Mode: mode}, nil
+}
+
+func (r Rule) Apply(d time.Duration) time.Duration {
+	if r.Step <= 0 {
+		return d
+	}
+	switch r.Mode {
+	case ModeUp:
+		if rest := d % r.Step; rest != 0 {
+			return d - rest + r.Step
+		}
+		return d
+	case ModeDown:
+		return d.Truncate(r.Step)
+	default:
+		return d.Round(r.Step)
+	}
+}
+
+// Rules of rounding for worklogs, tag rules override the default one.
+type Rules struct {
+	Default     Rule
+	MinDuration time.Duration
+	ByTag       map[string]Rule
+}
+
+// Default returns rules without rounding and with default minimal duration.
+func Default() *Rules {
+	return &Rules{MinDuration: DefaultMinDuration}
+}
+
+// ParseByTags parses tag rules in format 'tag=step/mode,tag=step'.
+func ParseByTags(val string) (map[string]Rule, error) {
+	res := make(map[string]Rule)
+	if len(strings.TrimSpace(val)) == 0 {
+		return res, nil
+	}
+	for _, tagRule := range strings.Split(val, ",") {
+		kv := strings.SplitN(tagRule, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("bad rule of tag '%s', expected format 'tag=step/mode'", tagRule)
+		}
+		rule, err := ParseRule(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		res[strings.TrimSpace(kv[0])] = rule
+	}
+	return res, nil
+}
+
+func (r *Rules) For(tag string) Rule {
+	if rule, ok := r.ByTag[tag]; ok {
+		return rule
+	}
+	return r.Default
+}
+
+// Apply rounds duration by rule of tag, returns false if duration isn't longer than minimal or is rounded to zero.
+// Duration equal to minimal is skipped as before minimal duration became configurable.
+func (r *Rules) Apply(tag string, d time.Duration) (time.Duration, bool) {
+	if d <= r.MinDuration {
+		return d, false
+	}
+	rounded := r.For(tag).Apply(d)
+	return rounded, rounded > 0
+}
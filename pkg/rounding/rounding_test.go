@@ -0,0 +1,70 @@
+package rounding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRule_Apply(t *testing.T) {
+	tests := []struct {
+		rule     string
+		duration time.Duration
+		expected time.Duration
+	}{
+		{"0s", 7 * time.Minute, 7 * time.Minute},
+		{"5m", 7 * time.Minute, 5 * time.Minute},
+		{"5m", 8 * time.Minute, 10 * time.Minute},
+		{"15m/up", 16 * time.Minute, 30 * time.Minute},
+		{"15m/up", 15 * time.Minute, 15 * time.Minute},
+		{"15m/down", 29 * time.Minute, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule+" "+tt.duration.String(), func(t *testing.T) {
+			rule, err := ParseRule(tt.rule)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rule.Apply(tt.duration))
+		})
+	}
+}
+
+func TestRules_Apply(t *testing.T) {
+	byTags, err := ParseByTags("review=15m/up, meeting=30m")
+	require.NoError(t, err)
+	rules := &Rules{
+		Default:     Rule{Step: 5 * time.Minute, Mode: ModeDown},
+		MinDuration: time.Minute,
+		ByTag:       byTags,
+	}
+
+	d, ok := rules.Apply("", 30*time.Second)
+	assert.False(t, ok, "less than minimal")
+	assert.Equal(t, 30*time.Second, d)
+
+	_, ok = rules.Apply("", 4*time.Minute)
+	assert.False(t, ok, "rounded to zero")
+
+	_, ok = rules.Apply("review", time.Minute)
+	assert.False(t, ok, "equal to minimal")
+
+	d, ok = rules.Apply("review", time.Minute+time.Second)
+	assert.True(t, ok, "longer than minimal")
+	assert.Equal(t, 15*time.Minute, d)
+
+	d, ok = rules.Apply("review", 4*time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, 15*time.Minute, d)
+
+	d, ok = rules.Apply("meeting", 20*time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Minute, d)
+}
+
+func TestParseByTags_BadFormat_Err(t *testing.T) {
+	_, err := ParseByTags("review")
+	assert.Error(t, err)
+	_, err = ParseByTags("review=15m/sideways")
+	assert.Error(t, err)
+}
@@ -0,0 +1,161 @@
+package timeline
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrskom/jwa-console/pkg/filedb"
+)
+
+// archiveLayout is a layout of month in names of archive tables, for example 'archive.2019-07'.
+const archiveLayout = "2006-01"
+
+// archiveTableFor returns table of archive for month of time.
+func (c *Component) archiveTableFor(at time.Time) string {
+	return c.archiveTable + "." + at.Local().Format(archiveLayout)
+}
+
+// archiveTables returns tables of archive by months, backups of old versions of them aren't included.
+func (c *Component) archiveTables() ([]string, error) {
+	tables, err := c.db.Tables(c.archiveTable + ".")
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if _, err := time.Parse(archiveLayout, strings.TrimPrefix(table, c.archiveTable+".")); err == nil {
+			res = append(res, table)
+		}
+	}
+
+	return res, nil
+}
+
+// getArchive returns archived records of table, missing table is an empty timeline.
+func (c *Component) getArchive(table string) (*Timeline, error) {
+	var res Timeline
+	if err := c.db.Get(table, &res); err != nil {
+		if filedb.IsNotExist(err) {
+			return &Timeline{List: make([]*Model, 0)}, nil
+		}
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// archive adds records to archive tables by month of start, records which are already archived are skipped.
+func (c *Component) archive(models []*Model) error {
+	byTable := make(map[string][]*Model)
+	tables := make([]string, 0)
+	for _, m := range models {
+		table := c.archiveTableFor(m.StartTime)
+		if _, ok := byTable[table]; !ok {
+			tables = append(tables, table)
+		}
+		byTable[table] = append(byTable[table], m)
+	}
+
+	for _, table := range tables {
+		archived, err := c.getArchive(table)
+		if err != nil {
+			return err
+		}
+		known := make(map[string]struct{})
+		for _, data := range encodeRecords(archived.List) {
+			known[string(data)] = struct{}{}
+		}
+		for i, data := range encodeRecords(byTable[table]) {
+			if _, ok := known[string(data)]; ok {
+				continue
+			}
+			known[string(data)] = struct{}{}
+			archived.Add(byTable[table][i])
+		}
+		sort.SliceStable(archived.List, func(i, j int) bool {
+			return archived.List[i].StartTime.Before(archived.List[j].StartTime)
+		})
+
+		data, err := marshalTimeline(archived)
+		if err != nil {
+			return err
+		}
+		if err := c.db.Set(table, json.RawMessage(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetArchive returns archived records matched by filter, From and Until of filter are required.
+func (c *Component) GetArchive(f *PublishFilter) (*Timeline, error) {
+	if f == nil || f.From.IsZero() || f.Until.IsZero() {
+		return nil, errors.New("range of dates is required for archive")
+	}
+
+	res := &Timeline{List: make([]*Model, 0)}
+	from := f.From.Local()
+	month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.Local)
+	for ; month.Before(f.Until); month = month.AddDate(0, 1, 0) {
+		archived, err := c.getArchive(c.archiveTableFor(month))
+		if err != nil {
+			return nil, err
+		}
+		for i, m := range archived.List {
+			if f.Match(i, m) {
+				res.Add(m)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// GetWithArchive returns archived records and records of timeline matched by filter, From and Until of filter are required.
+func (c *Component) GetWithArchive(f *PublishFilter) (*Timeline, error) {
+	res, err := c.GetArchive(f)
+	if err != nil {
+		return nil, err
+	}
+	tl, err := c.Get()
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range tl.List {
+		if f.Match(i, m) {
+			res.Add(m)
+		}
+	}
+
+	return res, nil
+}
+
+// ArchivePublished moves history of published records stored by old versions to archive,
+// the old table is kept as 'published.archived'. The lock is taken only if the old table exists.
+func (c *Component) ArchivePublished() error {
+	ok, err := c.db.Has(c.publishedTable)
+	if err != nil || !ok {
+		return err
+	}
+
+	if err := c.db.Lock(); err != nil {
+		return err
+	}
+	defer c.db.Unlock()
+
+	if ok, err = c.db.Has(c.publishedTable); err != nil || !ok {
+		return err
+	}
+	published, err := c.getArchive(c.publishedTable)
+	if err != nil {
+		return err
+	}
+	if err := c.archive(published.List); err != nil {
+		return err
+	}
+
+	return c.db.Rename(c.publishedTable, c.publishedTable+".archived")
+}
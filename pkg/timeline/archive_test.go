@@ -0,0 +1,84 @@
+package timeline
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent_Archive(t *testing.T) {
+	c := getTestComponent(t)
+
+	start := time.Date(2019, 7, 31, 10, 0, 0, 0, time.Local)
+	first := issueModel("A-1", start, time.Hour)
+	first.WorklogID = "1"
+	second := issueModel("A-2", start.AddDate(0, 0, 1), time.Hour)
+	second.WorklogID = "2"
+	second.Tag = "review"
+	require.NoError(t, c.archive([]*Model{second, first}))
+	require.NoError(t, c.archive([]*Model{first}))
+
+	for _, table := range []string{"archive.2019-07", "archive.2019-08"} {
+		archived, err := c.getArchive(table)
+		require.NoError(t, err)
+		assert.Len(t, archived.List, 1, table)
+	}
+
+	all, err := c.GetArchive(&PublishFilter{From: start.AddDate(0, 0, -1), Until: start.AddDate(0, 0, 2)})
+	require.NoError(t, err)
+	require.Len(t, all.List, 2)
+	assert.Equal(t, "A-1", all.List[0].Issue.Key)
+	assert.Equal(t, "A-2", all.List[1].Issue.Key)
+
+	tagged, err := c.GetArchive(&PublishFilter{From: start, Until: start.AddDate(0, 1, 0), Tags: []string{"review"}})
+	require.NoError(t, err)
+	require.Len(t, tagged.List, 1)
+	assert.Equal(t, "A-2", tagged.List[0].Issue.Key)
+
+	_, err = c.GetArchive(&PublishFilter{From: start})
+	assert.Error(t, err)
+}
+
+func TestComponent_ArchivePublished(t *testing.T) {
+	c := getTestComponent(t)
+
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.Local)
+	m := issueModel("A-1", start, time.Hour)
+	m.WorklogID = "1"
+	data, err := marshalTimeline(&Timeline{List: []*Model{m}})
+	require.NoError(t, err)
+	require.NoError(t, c.db.Set(c.publishedTable, json.RawMessage(data)))
+
+	require.NoError(t, c.ArchivePublished())
+	require.NoError(t, c.ArchivePublished())
+
+	ok, err := c.db.Has(c.publishedTable)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	archived, err := c.GetArchive(&PublishFilter{From: start, Until: start.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, archived.List, 1)
+	assert.Equal(t, "1", archived.List[0].WorklogID)
+}
+
+func TestComponent_GetWithArchive(t *testing.T) {
+	c := getTestComponent(t)
+
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.Local)
+	archived := issueModel("A-1", start, time.Hour)
+	archived.WorklogID = "1"
+	require.NoError(t, c.archive([]*Model{archived}))
+	_, err := c.Add(issueModel("A-2", start.Add(2*time.Hour), time.Hour))
+	require.NoError(t, err)
+	_, err = c.Add(issueModel("A-3", start.AddDate(0, 0, 1), time.Hour))
+	require.NoError(t, err)
+
+	tl, err := c.GetWithArchive(&PublishFilter{From: start, Until: start.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	require.Len(t, tl.List, 2)
+	assert.Equal(t, "A-1", tl.List[0].Issue.Key)
+	assert.Equal(t, "A-2", tl.List[1].Issue.Key)
+}
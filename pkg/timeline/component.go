@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,8 +12,10 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/andrskom/jwa-console/pkg/config"
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/issuecache"
 	"github.com/andrskom/jwa-console/pkg/jiraf"
-	"github.com/andrskom/jwa-console/pkg/storage/file"
+	"github.com/andrskom/jwa-console/pkg/profile"
 )
 
 const (
@@ -20,22 +23,55 @@ const (
 )
 
 type Component struct {
-	db          *file.DB
-	file        string
-	jiraFactory *jiraf.Factory
-	cfg         *config.Component
+	db             *filedb.JSON
+	table          string
+	publishedTable string
+	archiveTable   string
+	jiraFactory    *jiraf.Factory
+	cfg            *config.Component
+	profiles       *profile.Component
+	issues         *issuecache.Component
+	eventsTable    string
+	command        string
 }
 
-func NewComponent(db *file.DB, jiraFactory *jiraf.Factory, cfg *config.Component) *Component {
-	return &Component{db: db, jiraFactory: jiraFactory, file: "timeline.json", cfg: cfg}
+func NewComponent(
+	db *filedb.JSON,
+	jiraFactory *jiraf.Factory,
+	cfg *config.Component,
+	profiles *profile.Component,
+	issues *issuecache.Component,
+) *Component {
+	return &Component{
+		profiles:       profiles,
+		issues:         issues,
+		db:             db,
+		jiraFactory:    jiraFactory,
+		table:          "timeline",
+		publishedTable: "published",
+		archiveTable:   "archive",
+		cfg:            cfg,
+		eventsTable:    "events",
+	}
 }
 
 func (c *Component) Init() error {
-	data, err := json.Marshal(Timeline{List: make([]*Model, 0)})
+	isInit, err := c.IsInit()
 	if err != nil {
 		return err
 	}
-	return c.db.WriteData(c.file, data)
+	if isInit {
+		return errors.New("app already init")
+	}
+	if err := c.db.SetLines(c.eventsTable); err != nil {
+		return err
+	}
+	return c.writeTimeline(&Timeline{List: make([]*Model, 0)})
+}
+
+// IsInit checks if app is initialized.
+func (c *Component) IsInit() (bool, error) {
+	return c.db.Has(c.table)
 }
 
 func (c *Component) GetJiraFactory() *jiraf.Factory {
@@ -45,6 +81,10 @@ func (c *Component) GetJiraFactory() *jiraf.Factory {
 type StartOpts struct {
 	UsePrevDescription bool
 	Description        string
+	// NoTransition disables auto change of issue status on start.
+	NoTransition bool
+	// Refresh fetches issue from jira even if it's cached.
+	Refresh bool
 }
 
 func (o *StartOpts) Validate() error {
@@ -68,17 +108,17 @@ func (c *Component) BuildModel(taskID string, opts *StartOpts) (*Model, error) {
 		return nil, err
 	}
 
-	client, err := c.jiraFactory.GetClient()
+	profileName, err := c.profiles.Current()
 	if err != nil {
 		return nil, err
 	}
-
-	issue, resp, err := client.Issue.Get(taskID, nil)
+	issue, err := c.issues.Get(profileName, taskID, opts != nil && opts.Refresh)
 	if err != nil {
-		return nil, fmt.Errorf("uexpected jira response, while try to get issue: %s", resp.Status)
+		return nil, err
 	}
 
-	newModel := NewModel(issue)
+	newModel := NewModel(NewIssue(issue))
+	newModel.Profile = profileName
 	if opts != nil {
 		if opts.UsePrevDescription {
 			set := false
@@ -103,8 +143,9 @@ func (c *Component) BuildModel(taskID string, opts *StartOpts) (*Model, error) {
 	return newModel, nil
 }
 
-func (c *Component) Start(newModel *Model) (*Model, error) {
-
+// Start adds new running record to the timeline.
+// Jira is requested without lock of db, the timeline isn't saved if it's changed by another command meanwhile.
+func (c *Component) Start(newModel *Model, opts *StartOpts) (*Model, error) {
 	timeline, err := c.getTimeline()
 	if err != nil {
 		return nil, err
@@ -123,10 +164,20 @@ func (c *Component) Start(newModel *Model) (*Model, error) {
 		return nil, err
 	}
 
+	transit := len(cfg.AutoChangeStatusTo) > 0 && (opts == nil || !opts.NoTransition)
+	if len(cfg.StatusesForStart) != 0 || transit {
+		// status of cached issue can be stale, cached issue is used only if jira isn't available
+		issue, err := c.issues.Get(newModel.GetProfile(), newModel.Issue.Key, true)
+		if err != nil {
+			return nil, err
+		}
+		newModel.Issue = NewIssue(issue)
+	}
+
 	if len(cfg.StatusesForStart) != 0 {
 		hasStatus := false
 		for _, st := range cfg.StatusesForStart {
-			if newModel.Issue.Fields.Status.Name == st {
+			if newModel.Issue.Status == st {
 				hasStatus = true
 			}
 		}
@@ -134,19 +185,101 @@ func (c *Component) Start(newModel *Model) (*Model, error) {
 			return nil, fmt.Errorf(
 				"status of task must be '%s' for start, actual is '%s'",
 				strings.Join(cfg.StatusesForStart, ","),
-				newModel.Issue.Fields.Status.Name,
+				newModel.Issue.Status,
 			)
 		}
 	}
 
+	if transit {
+		if err := c.transitIssue(newModel.GetProfile(), newModel.Issue, cfg.AutoChangeStatusTo); err != nil {
+			return nil, err
+		}
+	}
+
 	timeline.Add(newModel)
-	if err := c.saveTimeline(timeline); err != nil {
+	if err := c.commit(timeline, EventStart); err != nil {
 		return nil, err
 	}
 	return newModel, nil
 }
 
+// transitIssue changes status of issue via available transition, does nothing if issue already has the status.
+func (c *Component) transitIssue(profileName string, issue *Issue, status string) error {
+	if strings.EqualFold(issue.Status, status) {
+		return nil
+	}
+
+	client, err := c.jiraFactory.GetClientFor(profileName)
+	if err != nil {
+		return err
+	}
+	transitions, resp, err := client.Issue.GetTransitions(issue.Key)
+	if err != nil {
+		if resp == nil {
+			return fmt.Errorf("can't get transitions of issue %s: %w", issue.Key, err)
+		}
+		return fmt.Errorf("unexpected jira response, while try to get transitions of issue %s: %s", issue.Key, resp.Status)
+	}
+
+	available := make([]string, 0, len(transitions))
+	for _, t := range transitions {
+		if !strings.EqualFold(t.To.Name, status) {
+			available = append(available, t.To.Name)
+			continue
+		}
+		if resp, err := client.Issue.DoTransition(issue.Key, t.ID); err != nil {
+			if resp == nil {
+				return fmt.Errorf("can't change status of issue %s: %w", issue.Key, err)
+			}
+			return fmt.Errorf("unexpected jira response, while try to change status of issue %s: %s", issue.Key, resp.Status)
+		}
+		issue.Status = t.To.Name
+		if err := c.issues.SetStatus(profileName, issue.Key, issue.Status); err != nil {
+			log.Printf("Can't save status of issue %s to cache: %s", issue.Key, err.Error())
+		}
+		return nil
+	}
+
+	return fmt.Errorf(
+		"issue %s has no transition to status '%s', available statuses: '%s', use --no-transition for start without it",
+		issue.Key,
+		status,
+		strings.Join(available, ","),
+	)
+}
+
+// Add inserts finished record to the timeline retroactively.
+func (c *Component) Add(newModel *Model) (int, error) {
+	if err := c.db.Lock(); err != nil {
+		return 0, err
+	}
+	defer c.db.Unlock()
+
+	if newModel.FinishTime.Sub(time.Now()) > 0 {
+		return 0, errors.New("can't add record finished in the future")
+	}
+
+	timeline, err := c.getTimeline()
+	if err != nil {
+		return 0, err
+	}
+
+	num, err := timeline.Insert(newModel)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.saveTimeline(timeline, EventAdd); err != nil {
+		return 0, err
+	}
+	return num, nil
+}
+
 func (c *Component) Stop() (*Model, error) {
+	if err := c.db.Lock(); err != nil {
+		return nil, err
+	}
+	defer c.db.Unlock()
+
 	timeline, err := c.getTimeline()
 	if err != nil {
 		return nil, err
@@ -160,7 +293,63 @@ func (c *Component) Stop() (*Model, error) {
 		return nil, errors.New("last task already finished")
 	}
 	model.Finish()
-	if err := c.saveTimeline(timeline); err != nil {
+	if err := c.saveTimeline(timeline, EventStop); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func (c *Component) Pause() (*Model, error) {
+	if err := c.db.Lock(); err != nil {
+		return nil, err
+	}
+	defer c.db.Unlock()
+
+	timeline, err := c.getTimeline()
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := timeline.GetCurrent()
+	if err != nil {
+		return nil, err
+	}
+	if model.IsFinished() {
+		return nil, errors.New("last task already finished")
+	}
+	if err := model.Pause(); err != nil {
+		return nil, err
+	}
+	if err := c.saveTimeline(timeline, EventPause); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func (c *Component) Resume() (*Model, error) {
+	if err := c.db.Lock(); err != nil {
+		return nil, err
+	}
+	defer c.db.Unlock()
+
+	timeline, err := c.getTimeline()
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := timeline.GetCurrent()
+	if err != nil {
+		return nil, err
+	}
+	if model.IsFinished() {
+		return nil, errors.New("last task already finished")
+	}
+	if err := model.Resume(); err != nil {
+		return nil, err
+	}
+	if err := c.saveTimeline(timeline, EventResume); err != nil {
 		return nil, err
 	}
 
@@ -179,59 +368,201 @@ func (c *Component) GetCurrent() (*Model, error) {
 	return model.GetCurrent()
 }
 
-func (c *Component) Publish() error {
-	jiraClient, err := c.jiraFactory.GetClient()
+// Publish sends finished records matched by filter to jira as worklogs.
+// Every sent record keeps id of worklog, so records are never sent twice and
+// publishing can be repeated after failure. Published records are moved to the archive.
+// The running record is handled by running policy.
+// Jira is requested without lock of db, the lock is taken for saving of every change of the timeline,
+// publishing stops with ErrConflict if the timeline is changed by another command meanwhile.
+func (c *Component) Publish(opts PublishOpts) error {
+	models, err := c.getTimeline()
 	if err != nil {
 		return err
 	}
-
-	models, err := c.getTimeline()
-	if err != nil {
+	if err := applyRunningPolicy(models, opts.Filter, opts.Running); err != nil {
 		return err
 	}
-	user, resp, err := jiraClient.User.GetSelf()
-	if err != nil {
-		return fmt.Errorf("unexpected response code while try to get user: %d", resp.StatusCode)
+	// stopped or split running record is saved before sending, later only ids of worklogs are saved.
+	if err := c.commit(models, EventPublish); err != nil {
+		return err
 	}
 
 	now := jira.Time(time.Now().Round(time.Second).Add(time.Millisecond))
-	lastSentIndex := 0
-	for i, model := range models.List {
-		if model.Duration() <= time.Minute {
-			log.Printf("%d [%s] Not sent, because duration less than minute", i, model.Issue.Key)
+	rules, err := c.RoundingFor(models)
+	if err != nil {
+		return err
+	}
+	clients := make(map[string]*publishClient)
+	sent := make([]int, 0)
+	plan := BuildPlan(models, opts, rules)
+	for _, item := range plan.Items {
+		if item.SkipReason == SkipReasonShort || item.SkipReason == SkipReasonRoundedZero {
+			log.Printf("%d [%s] Not sent, because %s, records are kept in timeline", item.Num, item.IssueKey, item.SkipReason)
+		}
+		if item.IsSkipped() {
 			continue
 		}
-		startTime := model.StartTime.Round(time.Second).Add(time.Millisecond)
-		comment := model.Description
-		if len(model.Tag) > 0 {
-			comment = "#" + model.Tag + " " + comment
+		pc, err := c.getPublishClient(clients, item.Profile)
+		if err != nil {
+			if saveErr := c.commitWorklogs(models); saveErr != nil {
+				log.Printf("Can't save ids of sent worklogs to file: %s", saveErr.Error())
+			}
+			return partialPublishErr(err, sent)
 		}
-		_, resp, err := jiraClient.Issue.AddWorklogRecord(model.Issue.Key, &jira.WorklogRecord{
-			Author:           user,
-			UpdateAuthor:     user,
+		startTime := item.Started.Add(time.Millisecond)
+		worklog, resp, err := pc.client.Issue.AddWorklogRecord(item.IssueKey, &jira.WorklogRecord{
+			Author:           pc.user,
+			UpdateAuthor:     pc.user,
 			Created:          &now,
 			Updated:          &now,
 			Started:          (*jira.Time)(&startTime),
-			TimeSpentSeconds: int(model.Duration().Seconds()),
-			IssueID:          model.Issue.ID,
-			Comment:          comment,
+			TimeSpentSeconds: item.TimeSpentSeconds,
+			IssueID:          item.IssueID,
+			Comment:          item.Comment,
 		})
 		if err != nil {
-			if saveErr := c.saveTimeline(&Timeline{List: models.List[lastSentIndex+1:]}); saveErr != nil {
-				log.Printf("Can't save not sent tasks to file, last sent %d", lastSentIndex)
+			if saveErr := c.commitWorklogs(models); saveErr != nil {
+				log.Printf("Can't save ids of sent worklogs to file: %s", saveErr.Error())
+			}
+			if resp == nil {
+				return partialPublishErr(
+					fmt.Errorf("can't send worklog #%d for issue %s: %w", item.Num, item.IssueKey, err),
+					sent,
+				)
 			}
 			log.Println(err.Error())
-			return fmt.Errorf(
+			return partialPublishErr(fmt.Errorf(
 				"unexpected response code while try to send worklog #%d: %d, for issue: %s",
-				i,
+				item.Num,
 				resp.StatusCode,
+				item.IssueKey,
+			), sent)
+		}
+		for _, num := range item.Records {
+			models.List[num].WorklogID = worklog.ID
+		}
+		sent = append(sent, item.Records...)
+		if err := c.commitWorklogs(models); err != nil {
+			return fmt.Errorf("worklog #%d sent as %s, but can't save it: %w", item.Num, worklog.ID, err)
+		}
+	}
+
+	planned := make(map[int]struct{})
+	for _, item := range plan.Items {
+		for _, num := range item.Records {
+			planned[num] = struct{}{}
+		}
+	}
+	if err := c.db.Lock(); err != nil {
+		return err
+	}
+	defer c.db.Unlock()
+
+	// timeline is reloaded, because it could be changed by another command while worklogs were sent.
+	cur, err := c.getTimeline()
+	if err != nil {
+		return err
+	}
+	archived := make(map[int]struct{})
+	for i, model := range models.List {
+		// records which are skipped as too short aren't sent, they are kept in timeline.
+		if _, ok := planned[i]; !ok || !model.IsFinished() || !model.IsPublished() {
+			continue
+		}
+		if num := cur.find(model); num >= 0 {
+			archived[num] = struct{}{}
+		}
+	}
+	rest := &Timeline{Seq: cur.Seq, List: make([]*Model, 0)}
+	published := make([]*Model, 0)
+	for i, model := range cur.List {
+		if _, ok := archived[i]; ok {
+			published = append(published, model)
+			continue
+		}
+		rest.Add(model)
+	}
+	if err := c.archive(published); err != nil {
+		return err
+	}
+
+	return c.saveTimeline(rest, EventPublish)
+}
+
+// commitWorklogs saves ids of sent worklogs. If timeline is changed by another command while worklogs were sent,
+// timeline is reloaded and ids are set to records matched by start time and issue, so sent worklogs aren't lost.
+func (c *Component) commitWorklogs(models *Timeline) error {
+	err := c.commit(models, EventWorklog)
+	if err != ErrConflict {
+		return err
+	}
+
+	if err := c.db.Lock(); err != nil {
+		return err
+	}
+	defer c.db.Unlock()
+
+	cur, err := c.getTimeline()
+	if err != nil {
+		return err
+	}
+	for _, model := range models.List {
+		if !model.IsPublished() {
+			continue
+		}
+		num := cur.find(model)
+		if num < 0 {
+			return fmt.Errorf(
+				"record of issue %s started at %s is removed from timeline",
 				model.Issue.Key,
+				model.StartTime.Format(time.RFC3339),
 			)
 		}
-		lastSentIndex = i
+		cur.List[num].WorklogID = model.WorklogID
+	}
+
+	return c.saveTimeline(cur, EventWorklog)
+}
+
+// partialPublishErr adds numbers of records which are already sent to error of publishing.
+func partialPublishErr(err error, sent []int) error {
+	if len(sent) == 0 {
+		return err
+	}
+	nums := make([]string, 0, len(sent))
+	for _, num := range sent {
+		nums = append(nums, strconv.Itoa(num))
 	}
+	return fmt.Errorf(
+		"%w, records %s are published and keep ids of worklogs, publish again to send the rest",
+		err,
+		strings.Join(nums, ","),
+	)
+}
+
+type publishClient struct {
+	client *jira.Client
+	user   *jira.User
+}
 
-	return c.saveTimeline(&Timeline{List: make([]*Model, 0)})
+// getPublishClient returns client and user of profile, clients are cached.
+func (c *Component) getPublishClient(clients map[string]*publishClient, profileName string) (*publishClient, error) {
+	if pc, ok := clients[profileName]; ok {
+		return pc, nil
+	}
+	client, err := c.jiraFactory.GetClientFor(profileName)
+	if err != nil {
+		return nil, err
+	}
+	user, resp, err := client.User.GetSelf()
+	if err != nil {
+		if resp == nil {
+			return nil, fmt.Errorf("can't get user of profile %s: %w", profileName, err)
+		}
+		return nil, fmt.Errorf("unexpected response code while try to get user of profile %s: %d", profileName, resp.StatusCode)
+	}
+	clients[profileName] = &publishClient{client: client, user: user}
+	return clients[profileName], nil
 }
 
 type EditOpts struct {
@@ -242,12 +573,20 @@ type EditOpts struct {
 }
 
 func (c *Component) Edit(num int, opts EditOpts) error {
+	if err := c.db.Lock(); err != nil {
+		return err
+	}
+	defer c.db.Unlock()
+
 	tl, err := c.getTimeline()
 	if err != nil {
 		return err
 	}
 	if len(tl.List) <= num {
-		return errors.New("bad number of record")
+		return ErrBadRecordNum
+	}
+	if tl.List[num].IsPublished() {
+		return ErrPublished
 	}
 	if opts.Description != nil {
 		tl.List[num].Description = *opts.Description
@@ -256,6 +595,9 @@ func (c *Component) Edit(num int, opts EditOpts) error {
 		if num > 0 && opts.StartTime.Sub(tl.List[num-1].FinishTime) < 0 {
 			return errors.New("can't set start time before finish time previously record")
 		}
+		if len(tl.List[num].Pauses) > 0 && tl.List[num].Pauses[0].StartTime.Sub(*opts.StartTime) < 0 {
+			return errors.New("can't set start time after start of the first pause")
+		}
 		tl.List[num].StartTime = *opts.StartTime
 	}
 	if opts.FinishTime != nil {
@@ -265,46 +607,202 @@ func (c *Component) Edit(num int, opts EditOpts) error {
 		if num+1 != len(tl.List) && tl.List[num+1].StartTime.Sub(*opts.FinishTime) < 0 {
 			return errors.New("can't set finish time after start time next record")
 		}
+		if pauses := tl.List[num].Pauses; len(pauses) > 0 &&
+			opts.FinishTime.Sub(pauses[len(pauses)-1].FinishTime) < 0 {
+			return errors.New("can't set finish time before finish of the last pause")
+		}
 		tl.List[num].FinishTime = *opts.FinishTime
 	}
 	if opts.Task != nil {
-		client, err := c.jiraFactory.GetClient()
+		issue, err := c.getIssue(tl.List[num].GetProfile(), *opts.Task)
 		if err != nil {
 			return err
 		}
-		issue, resp, err := client.Issue.Get(*opts.Task, nil)
+
+		tl.List[num].Issue = issue
+	}
+	return c.saveTimeline(tl, EventEdit)
+}
+
+func (c *Component) Remove(num int) error {
+	if err := c.db.Lock(); err != nil {
+		return err
+	}
+	defer c.db.Unlock()
+
+	tl, err := c.getTimeline()
+	if err != nil {
+		return err
+	}
+	if err := tl.Remove(num); err != nil {
+		return err
+	}
+	return c.saveTimeline(tl, EventDelete)
+}
+
+// Split record at time, task is an issue key of the second record, nil keeps the issue of record.
+func (c *Component) Split(num int, at time.Time, task *string) error {
+	if err := c.db.Lock(); err != nil {
+		return err
+	}
+	defer c.db.Unlock()
+
+	tl, err := c.getTimeline()
+	if err != nil {
+		return err
+	}
+	if num < 0 || len(tl.List) <= num {
+		return ErrBadRecordNum
+	}
+	issue := tl.List[num].Issue
+	if task != nil {
+		issue, err = c.getIssue(tl.List[num].GetProfile(), *task)
 		if err != nil {
-			return fmt.Errorf(
-				"unexpected response code while try to get jira issue: %s, respo code: %d",
-				*opts.Task,
-				resp.StatusCode,
-			)
+			return err
 		}
+	}
+	if err := tl.Split(num, at, issue); err != nil {
+		return err
+	}
+	return c.saveTimeline(tl, EventSplit)
+}
 
-		tl.List[num].Issue = issue
+func (c *Component) Merge(num int, nextNum int) error {
+	if err := c.db.Lock(); err != nil {
+		return err
 	}
-	return c.saveTimeline(tl)
+	defer c.db.Unlock()
+
+	tl, err := c.getTimeline()
+	if err != nil {
+		return err
+	}
+	if err := tl.Merge(num, nextNum); err != nil {
+		return err
+	}
+	return c.saveTimeline(tl, EventMerge)
 }
 
-func (c *Component) getTimeline() (*Timeline, error) {
-	data, err := c.db.ReadData(c.file)
+func (c *Component) getIssue(profileName string, key string) (*Issue, error) {
+	issue, err := c.issues.Get(profileName, key, false)
 	if err != nil {
 		return nil, err
 	}
+	return NewIssue(issue), nil
+}
 
-	var res Timeline
-	if err := json.Unmarshal(data, &res); err != nil {
-		return nil, err
+// load reads the last snapshot of timeline and replays events of log over it.
+func (c *Component) load() (*Timeline, []*Event, error) {
+	var t Timeline
+	if err := c.db.Get(c.table, &t); err != nil {
+		return nil, nil, err
+	}
+
+	events := make([]*Event, 0)
+	err := c.db.GetLines(c.eventsTable, func(decode func(object interface{}) error) error {
+		var e Event
+		if err := decode(&e); err != nil {
+			return err
+		}
+		events = append(events, &e)
+		return nil
+	})
+	if err != nil && !filedb.IsNotExist(err) {
+		return nil, nil, err
+	}
+	for _, e := range events {
+		if e.Seq <= t.Seq {
+			continue
+		}
+		list, err := applyOps(t.List, e.Ops)
+		if err != nil {
+			return nil, nil, fmt.Errorf("broken log of timeline at event %d: %w", e.Seq, err)
+		}
+		t.List = list
+		t.Seq = e.Seq
+	}
+
+	return &t, events, nil
+}
+
+func (c *Component) getTimeline() (*Timeline, error) {
+	t, _, err := c.load()
+	return t, err
+}
+
+// saveTimeline appends changes of timeline to log as event of type.
+// Timeline must be read by getTimeline, it isn't saved if log is changed since reading.
+func (c *Component) saveTimeline(t *Timeline, eventType string) error {
+	cur, events, err := c.load()
+	if err != nil {
+		return err
+	}
+	if cur.Seq != t.Seq {
+		return ErrConflict
+	}
+	ops := diffRecords(cur.List, t.List)
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := c.apply(cur, events, &Event{Type: eventType, Ops: ops}); err != nil {
+		return err
+	}
+	t.Seq = cur.Seq
+
+	return nil
+}
+
+// commit saves timeline with lock of db, it's used after requests to jira, which are done without the lock.
+func (c *Component) commit(t *Timeline, eventType string) error {
+	if err := c.db.Lock(); err != nil {
+		return err
+	}
+	defer c.db.Unlock()
+
+	return c.saveTimeline(t, eventType)
+}
+
+// apply appends event to log and applies it to timeline, log is compacted if it's too long.
+func (c *Component) apply(t *Timeline, events []*Event, e *Event) error {
+	list, err := applyOps(t.List, e.Ops)
+	if err != nil {
+		return err
+	}
+	e.Seq = t.Seq + 1
+	e.Time = time.Now()
+	e.Command = c.command
+	if err := c.db.Append(c.eventsTable, e); err != nil {
+		return err
+	}
+	t.List = list
+	t.Seq = e.Seq
+
+	events = append(events, e)
+	if len(events) < compactLimit {
+		return nil
+	}
+	return c.compact(t, events)
+}
+
+// compact writes snapshot of timeline and keeps in log only the last events, which are available for undo.
+func (c *Component) compact(t *Timeline, events []*Event) error {
+	if err := c.writeTimeline(t); err != nil {
+		return err
+	}
+	kept := make([]interface{}, 0, journalLimit)
+	for _, e := range events[len(events)-journalLimit:] {
+		kept = append(kept, e)
 	}
 
-	return &res, nil
+	return c.db.SetLines(c.eventsTable, kept...)
 }
 
-func (c *Component) saveTimeline(t *Timeline) error {
-	data, err := json.Marshal(t)
+// writeTimeline writes snapshot of timeline.
+func (c *Component) writeTimeline(t *Timeline) error {
+	data, err := marshalTimeline(t)
 	if err != nil {
 		return err
 	}
 
-	return c.db.WriteData(c.file, data)
+	return c.db.Set(c.table, json.RawMessage(data))
 }
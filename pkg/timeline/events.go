@@ -0,0 +1,273 @@
+package timeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// journalLimit is a number of the last events kept in log after compaction, they are available for undo.
+	journalLimit = 30
+	// compactLimit is a number of events in log which triggers compaction.
+	compactLimit = 2 * journalLimit
+)
+
+// Types of timeline events.
+const (
+	EventStart   = "start"
+	EventAdd     = "add"
+	EventStop    = "stop"
+	EventPause   = "pause"
+	EventResume  = "resume"
+	EventEdit    = "edit"
+	EventDelete  = "delete"
+	EventSplit   = "split"
+	EventMerge   = "merge"
+	EventPublish = "publish"
+	// EventWorklog saves id of sent worklog, it can't be undone.
+	EventWorklog = "worklog"
+	EventUndo    = "undo"
+	EventRedo    = "redo"
+)
+
+// Kinds of operations with records.
+const (
+	OpInsert = "insert"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+	ErrConflict      = errors.New("timeline was changed by another jwac, try again")
+	errBadOp         = errors.New("record isn't in the expected state")
+)
+
+// Op is a change of one record, ops of event are applied one by one.
+// Record is a new state of record, Prev is the state before change.
+type Op struct {
+	Op     string
+	Num    int
+	Record *Model `json:",omitempty"`
+	Prev   *Model `json:",omitempty"`
+}
+
+// Event is a line of append-only log of timeline, the timeline is a replay of events over the last snapshot.
+type Event struct {
+	Seq     int
+	Time    time.Time
+	Type    string
+	Command string
+	// Reverts is a seq of event which is reverted by undo or applied again by redo.
+	Reverts int `json:",omitempty"`
+	Ops     []*Op
+}
+
+// Journal is a history of events available for undo and redo.
+// Undone events are moved to redo list and dropped by next event.
+// Events before publish aren't available, because worklogs are already sent.
+type Journal struct {
+	Entries []*Event
+	Undone  []*Event
+}
+
+// buildJournal replays undo and redo over events.
+func buildJournal(events []*Event) *Journal {
+	j := &Journal{}
+	for _, e := range events {
+		switch e.Type {
+		case EventPublish, EventWorklog:
+			j.Entries = nil
+			j.Undone = nil
+		case EventUndo:
+			if n := len(j.Entries); n > 0 && j.Entries[n-1].Seq == e.Reverts {
+				j.Undone = append(j.Undone, j.Entries[n-1])
+				j.Entries = j.Entries[:n-1]
+			}
+		case EventRedo:
+			if n := len(j.Undone); n > 0 && j.Undone[n-1].Seq == e.Reverts {
+				j.Entries = append(j.Entries, j.Undone[n-1])
+				j.Undone = j.Undone[:n-1]
+			}
+		default:
+			j.Entries = append(j.Entries, e)
+			j.Undone = nil
+		}
+	}
+	return j
+}
+
+// diffRecords returns ops which change list from to list to.
+func diffRecords(from []*Model, to []*Model) []*Op {
+	fromData := encodeRecords(from)
+	toData := encodeRecords(to)
+
+	// lcs[i][j] is a length of common subsequence of from[i:] and to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if bytes.Equal(fromData[i], toData[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]*Op, 0)
+	i, j, num := 0, 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && bytes.Equal(fromData[i], toData[j]):
+			i++
+			j++
+			num++
+		case i < len(from) && (j == len(to) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, &Op{Op: OpDelete, Num: num, Prev: from[i]})
+			i++
+		default:
+			// changed record is a delete and an insert at the same position
+			if n := len(ops); n > 0 && ops[n-1].Op == OpDelete && ops[n-1].Num == num {
+				ops[n-1].Op = OpUpdate
+				ops[n-1].Record = to[j]
+			} else {
+				ops = append(ops, &Op{Op: OpInsert, Num: num, Record: to[j]})
+			}
+			j++
+			num++
+		}
+	}
+	return ops
+}
+
+// applyOps applies ops to list, it checks that records are in the expected state.
+func applyOps(list []*Model, ops []*Op) ([]*Model, error) {
+	res := append(make([]*Model, 0, len(list)), list...)
+	for _, op := range ops {
+		if op.Num < 0 || op.Num > len(res) || (op.Op != OpInsert && op.Num == len(res)) {
+			return nil, fmt.Errorf("%w, record #%d doesn't exist", errBadOp, op.Num)
+		}
+		if op.Op != OpInsert && !sameRecord(res[op.Num], op.Prev) {
+			return nil, fmt.Errorf("%w, record #%d is changed", errBadOp, op.Num)
+		}
+		switch op.Op {
+		case OpInsert:
+			res = append(res, nil)
+			copy(res[op.Num+1:], res[op.Num:])
+			res[op.Num] = op.Record
+		case OpUpdate:
+			res[op.Num] = op.Record
+		case OpDelete:
+			res = append(res[:op.Num], res[op.Num+1:]...)
+		default:
+			return nil, fmt.Errorf("unexpected op '%s'", op.Op)
+		}
+	}
+	return res, nil
+}
+
+// invertOps returns ops which revert ops.
+func invertOps(ops []*Op) []*Op {
+	res := make([]*Op, 0, len(ops))
+	for i := len(ops) - 1; i >= 0; i-- {
+		op := ops[i]
+		switch op.Op {
+		case OpInsert:
+			res = append(res, &Op{Op: OpDelete, Num: op.Num, Prev: op.Record})
+		case OpUpdate:
+			res = append(res, &Op{Op: OpUpdate, Num: op.Num, Record: op.Prev, Prev: op.Record})
+		case OpDelete:
+			res = append(res, &Op{Op: OpInsert, Num: op.Num, Record: op.Prev})
+		}
+	}
+	return res
+}
+
+func encodeRecords(list []*Model) [][]byte {
+	res := make([][]byte, len(list))
+	for i, m := range list {
+		res[i], _ = json.Marshal(m)
+	}
+	return res
+}
+
+func sameRecord(a *Model, b *Model) bool {
+	aData, _ := json.Marshal(a)
+	bData, _ := json.Marshal(b)
+	return bytes.Equal(aData, bData)
+}
+
+// SetCommand sets the command which is written to log with every timeline event.
+func (c *Component) SetCommand(command string) {
+	c.command = command
+}
+
+// Undo reverts the last event.
+func (c *Component) Undo() (*Event, error) {
+	if err := c.db.Lock(); err != nil {
+		return nil, err
+	}
+	defer c.db.Unlock()
+
+	t, events, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	j := buildJournal(events)
+	if len(j.Entries) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	entry := j.Entries[len(j.Entries)-1]
+	ops := invertOps(entry.Ops)
+	for _, op := range ops {
+		if op.Op != OpInsert && op.Prev.IsPublished() {
+			return nil, fmt.Errorf("%w, can't undo '%s'", ErrPublished, entry.Command)
+		}
+	}
+
+	if err := c.apply(t, events, &Event{Type: EventUndo, Reverts: entry.Seq, Ops: ops}); err != nil {
+		return nil, fmt.Errorf("can't undo '%s': %w", entry.Command, err)
+	}
+	return entry, nil
+}
+
+// Redo applies the last undone event again.
+func (c *Component) Redo() (*Event, error) {
+	if err := c.db.Lock(); err != nil {
+		return nil, err
+	}
+	defer c.db.Unlock()
+
+	t, events, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	j := buildJournal(events)
+	if len(j.Undone) == 0 {
+		return nil, ErrNothingToRedo
+	}
+	entry := j.Undone[len(j.Undone)-1]
+
+	if err := c.apply(t, events, &Event{Type: EventRedo, Reverts: entry.Seq, Ops: entry.Ops}); err != nil {
+		return nil, fmt.Errorf("can't redo '%s': %w", entry.Command, err)
+	}
+	return entry, nil
+}
+
+// History returns events available for undo and redo.
+func (c *Component) History() (*Journal, error) {
+	_, events, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	return buildJournal(events), nil
+}
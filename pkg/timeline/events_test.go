@@ -0,0 +1,127 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/config"
+	"github.com/andrskom/jwa-console/pkg/internal/testutil"
+	"github.com/andrskom/jwa-console/pkg/profile"
+)
+
+func getTestComponent(t *testing.T) *Component {
+	db := testutil.NewDB(t)
+	profiles := profile.NewComponent(db)
+	cfg := config.NewComponent(db, profiles)
+	require.NoError(t, cfg.Init())
+	c := NewComponent(db, nil, cfg, profiles, nil)
+	require.NoError(t, c.Init())
+
+	return c
+}
+
+func TestComponent_UndoRedo(t *testing.T) {
+	c := getTestComponent(t)
+	c.SetCommand("jwac add")
+
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.UTC)
+	_, err := c.Add(finishedModel(start, time.Hour))
+	require.NoError(t, err)
+	c.SetCommand("jwac rm 0")
+	require.NoError(t, c.Remove(0))
+
+	entry, err := c.Undo()
+	require.NoError(t, err)
+	assert.Equal(t, "jwac rm 0", entry.Command)
+	tl, err := c.Get()
+	require.NoError(t, err)
+	assert.Len(t, tl.List, 1)
+
+	_, err = c.Redo()
+	require.NoError(t, err)
+	tl, err = c.Get()
+	require.NoError(t, err)
+	assert.Len(t, tl.List, 0)
+	_, err = c.Redo()
+	assert.Equal(t, ErrNothingToRedo, err)
+
+	journal, err := c.History()
+	require.NoError(t, err)
+	assert.Len(t, journal.Entries, 2)
+}
+
+func TestDiffRecords(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.UTC)
+	a := issueModel("A-1", start, time.Hour)
+	b := issueModel("A-2", start.Add(time.Hour), time.Hour)
+	c := issueModel("A-3", start.Add(2*time.Hour), time.Hour)
+	changed := issueModel("A-2", start.Add(time.Hour), 2*time.Hour)
+
+	tests := []struct {
+		name string
+		from []*Model
+		to   []*Model
+		ops  int
+	}{
+		{"equal", []*Model{a, b}, []*Model{a, b}, 0},
+		{"insert", []*Model{a, c}, []*Model{a, b, c}, 1},
+		{"delete", []*Model{a, b, c}, []*Model{a, c}, 1},
+		{"update", []*Model{a, b, c}, []*Model{a, changed, c}, 1},
+		{"all", []*Model{a, b}, []*Model{c}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := diffRecords(tt.from, tt.to)
+			assert.Len(t, ops, tt.ops)
+
+			list, err := applyOps(tt.from, ops)
+			require.NoError(t, err)
+			assert.Equal(t, encodeRecords(tt.to), encodeRecords(list))
+
+			list, err = applyOps(list, invertOps(ops))
+			require.NoError(t, err)
+			assert.Equal(t, encodeRecords(tt.from), encodeRecords(list))
+		})
+	}
+}
+
+func TestComponent_SaveTimeline_Conflict(t *testing.T) {
+	c := getTestComponent(t)
+
+	tl, err := c.Get()
+	require.NoError(t, err)
+	_, err = c.Add(finishedModel(time.Date(2019, 7, 7, 10, 0, 0, 0, time.UTC), time.Hour))
+	require.NoError(t, err)
+
+	tl.List = append(tl.List, finishedModel(time.Date(2019, 7, 7, 12, 0, 0, 0, time.UTC), time.Hour))
+	assert.Equal(t, ErrConflict, c.saveTimeline(tl, EventAdd))
+}
+
+func TestComponent_Compact(t *testing.T) {
+	c := getTestComponent(t)
+
+	start := time.Date(2019, 7, 7, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < compactLimit; i++ {
+		_, err := c.Add(finishedModel(start.Add(time.Duration(i)*time.Hour), time.Minute))
+		require.NoError(t, err)
+	}
+
+	var snapshot Timeline
+	require.NoError(t, c.db.Get(c.table, &snapshot))
+	assert.Equal(t, compactLimit, snapshot.Seq)
+	assert.Len(t, snapshot.List, compactLimit)
+
+	journal, err := c.History()
+	require.NoError(t, err)
+	assert.Len(t, journal.Entries, journalLimit)
+
+	_, err = c.Undo()
+	require.NoError(t, err)
+	tl, err := c.Get()
+	require.NoError(t, err)
+	assert.Len(t, tl.List, compactLimit-1)
+	assert.Equal(t, compactLimit+1, tl.Seq)
+}
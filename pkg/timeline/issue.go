@@ -0,0 +1,83 @@
+package timeline
+
+import (
+	"encoding/json"
+
+	"github.com/andrskom/jwa-console/pkg/issuecache"
+)
+
+// Issue is a snapshot of jira issue which is stored in record.
+// It contains only data which is needed for tracking and publishing.
+type Issue struct {
+	Key     string `json:"key"`
+	ID      string `json:"id"`
+	Summary string `json:"summary"`
+	Status  string `json:"status"`
+	Project string `json:"project,omitempty"`
+	Epic    string `json:"epic,omitempty"`
+}
+
+func NewIssue(issue *issuecache.Issue) *Issue {
+	return &Issue{
+		Key:     issue.Key,
+		ID:      issue.ID,
+		Summary: issue.Summary,
+		Status:  issue.Status,
+		Project: issue.Project,
+		Epic:    issue.Epic,
+	}
+}
+
+// legacyIssue is a part of go-jira issue which was stored in records before Issue.
+type legacyIssue struct {
+	Key    string `json:"key"`
+	ID     string `json:"id"`
+	Fields *struct {
+		Summary string `json:"summary"`
+		Status  *struct {
+			Name string `json:"name"`
+		} `json:"status"`
+		Project struct {
+			Key string `json:"key"`
+		} `json:"project"`
+		Epic *struct {
+			Key string `json:"key"`
+		} `json:"epic"`
+	} `json:"fields"`
+}
+
+// isLegacyIssue checks if issue json is a go-jira issue.
+func isLegacyIssue(data []byte) bool {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	_, ok := probe["fields"]
+	return ok
+}
+
+// UnmarshalJSON reads issue and go-jira issue stored by old versions.
+func (i *Issue) UnmarshalJSON(data []byte) error {
+	if !isLegacyIssue(data) {
+		type issue Issue
+		return json.Unmarshal(data, (*issue)(i))
+	}
+
+	var legacy legacyIssue
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	*i = Issue{Key: legacy.Key, ID: legacy.ID}
+	if legacy.Fields == nil {
+		return nil
+	}
+	i.Summary = legacy.Fields.Summary
+	i.Project = legacy.Fields.Project.Key
+	if legacy.Fields.Status != nil {
+		i.Status = legacy.Fields.Status.Name
+	}
+	if legacy.Fields.Epic != nil {
+		i.Epic = legacy.Fields.Epic.Key
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package timeline
+
+import (
+	"encoding/json"
+
+	"github.com/andrskom/jwa-console/pkg/migration"
+)
+
+// Documents returns versioned documents of timeline with migrations to the current versions.
+func (c *Component) Documents() []*migration.Document {
+	timelineMigrations := map[int]migration.Func{
+		0: convertLegacyIssues,
+	}
+	return []*migration.Document{
+		{Table: c.table, VersionField: "Version", Version: TimelineVersion, Migrations: timelineMigrations},
+		{Table: c.publishedTable, VersionField: "Version", Version: TimelineVersion, Migrations: timelineMigrations},
+		{Tables: c.archiveTables, VersionField: "Version", Version: TimelineVersion, Migrations: timelineMigrations},
+	}
+}
+
+// rawTimeline is a timeline with not decoded issues.
+type rawTimeline struct {
+	List []*struct {
+		Issue json.RawMessage
+	}
+}
+
+// hasLegacyIssues checks if timeline json contains go-jira issues.
+func hasLegacyIssues(data []byte) (bool, error) {
+	var raw rawTimeline
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return false, err
+	}
+	for _, m := range raw.List {
+		if isLegacyIssue(m.Issue) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// convertLegacyIssues rewrites timeline json with issue snapshots instead of go-jira issues stored by old versions.
+func convertLegacyIssues(data []byte) ([]byte, error) {
+	legacy, err := hasLegacyIssues(data)
+	if err != nil || !legacy {
+		return data, err
+	}
+	var t Timeline
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return json.Marshal(t)
+}
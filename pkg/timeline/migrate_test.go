@@ -0,0 +1,64 @@
+package timeline
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/migration"
+)
+
+func TestComponent_Documents(t *testing.T) {
+	c := getTestComponent(t)
+	legacy := `{"List":[{"Finished":true,"Issue":{"id":"10","key":"A-1","fields":{` +
+		`"summary":"Task","status":{"name":"Open","self":"https://jira"},"project":{"key":"A"},"comment":{}}}}]}`
+	require.NoError(t, c.db.Set(c.table, json.RawMessage(legacy)))
+
+	registry := migration.NewRegistry(c.db, c.profiles)
+	registry.Register(c.Documents()...)
+	require.NoError(t, registry.Run())
+
+	var data json.RawMessage
+	require.NoError(t, c.db.Get(c.table, &data))
+	legacyLeft, err := hasLegacyIssues(data)
+	require.NoError(t, err)
+	assert.False(t, legacyLeft)
+	version, err := migration.ReadVersion(data, "Version")
+	require.NoError(t, err)
+	assert.Equal(t, TimelineVersion, version)
+
+	var backup json.RawMessage
+	require.NoError(t, c.db.Get(c.table+".v0", &backup))
+	assert.Equal(t, legacy, string(backup))
+
+	tl, err := c.Get()
+	require.NoError(t, err)
+	require.Len(t, tl.List, 1)
+	assert.Equal(t, &Issue{Key: "A-1", ID: "10", Summary: "Task", Status: "Open", Project: "A"}, tl.List[0].Issue)
+}
+
+func TestComponent_Documents_Archive(t *testing.T) {
+	c := getTestComponent(t)
+	legacy := `{"List":[{"Finished":true,"WorklogID":"1","Issue":{"id":"10","key":"A-1","fields":{` +
+		`"summary":"Task","status":{"name":"Open","self":"https://jira"},"project":{"key":"A"},"comment":{}}}}]}`
+	require.NoError(t, c.db.Set("archive.2019-07", json.RawMessage(legacy)))
+
+	registry := migration.NewRegistry(c.db, c.profiles)
+	registry.Register(c.Documents()...)
+	require.NoError(t, registry.Run())
+	require.NoError(t, registry.Run())
+
+	archived, err := c.getArchive("archive.2019-07")
+	require.NoError(t, err)
+	require.Len(t, archived.List, 1)
+	assert.Equal(t, &Issue{Key: "A-1", ID: "10", Summary: "Task", Status: "Open", Project: "A"}, archived.List[0].Issue)
+
+	tables, err := c.archiveTables()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"archive.2019-07"}, tables)
+	var backup json.RawMessage
+	require.NoError(t, c.db.Get("archive.2019-07.v0", &backup))
+	assert.Equal(t, legacy, string(backup))
+}
@@ -1,24 +1,54 @@
 package timeline
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 
-	"github.com/andygrunwald/go-jira"
+	"github.com/andrskom/jwa-console/pkg/profile"
 )
 
-var ErrTimelineEmpty = errors.New("timeline is empty")
+var (
+	ErrTimelineEmpty = errors.New("timeline is empty")
+	ErrNotPaused     = errors.New("task is not paused")
+	ErrPaused        = errors.New("task is already paused")
+	ErrOverlap       = errors.New("record overlaps existing record")
+	ErrBadRecordNum  = errors.New("bad number of record")
+	ErrPublished     = errors.New("record is already published")
+)
+
+type Pause struct {
+	StartTime  time.Time
+	FinishTime time.Time
+}
+
+func (p *Pause) IsFinished() bool {
+	return !p.FinishTime.IsZero()
+}
+
+// Duration of pause, not finished pause lasts till now.
+func (p *Pause) Duration() time.Duration {
+	if !p.IsFinished() {
+		return time.Now().Sub(p.StartTime)
+	}
+	return p.FinishTime.Sub(p.StartTime)
+}
 
 type Model struct {
 	Finished    bool
 	StartTime   time.Time
 	FinishTime  time.Time
 	Description string
-	Issue       *jira.Issue
+	Issue       *Issue
 	Tag         string
+	Pauses      []*Pause
+	// WorklogID is id of jira worklog which the record was published as.
+	WorklogID string
+	// Profile is a profile of jira which the record was started under.
+	Profile string
 }
 
-func NewModel(issue *jira.Issue) *Model {
+func NewModel(issue *Issue) *Model {
 	return &Model{
 		StartTime: time.Now(),
 		Issue:     issue,
@@ -29,23 +59,81 @@ func (m *Model) IsFinished() bool {
 	return m.Finished
 }
 
+// Finish the task, if task is paused, it's finished at the moment of pause.
 func (m *Model) Finish() {
 	m.Finished = true
 	m.FinishTime = time.Now()
+	if m.IsPaused() {
+		m.FinishTime = m.Pauses[len(m.Pauses)-1].StartTime
+		m.Pauses = m.Pauses[:len(m.Pauses)-1]
+	}
+}
+
+// GetProfile returns profile of record, records without profile belong to default profile.
+func (m *Model) GetProfile() string {
+	if len(m.Profile) == 0 {
+		return profile.Default
+	}
+	return m.Profile
+}
+
+func (m *Model) IsPublished() bool {
+	return len(m.WorklogID) > 0
+}
+
+func (m *Model) IsPaused() bool {
+	return len(m.Pauses) > 0 && !m.Pauses[len(m.Pauses)-1].IsFinished()
+}
+
+func (m *Model) Pause() error {
+	if m.IsPaused() {
+		return ErrPaused
+	}
+	m.Pauses = append(m.Pauses, &Pause{StartTime: time.Now()})
+	return nil
+}
+
+func (m *Model) Resume() error {
+	if !m.IsPaused() {
+		return ErrNotPaused
+	}
+	m.Pauses[len(m.Pauses)-1].FinishTime = time.Now()
+	return nil
+}
+
+// PausesDuration is a sum of all pauses inside the task.
+func (m *Model) PausesDuration() time.Duration {
+	res := time.Duration(0)
+	for _, p := range m.Pauses {
+		res += p.Duration()
+	}
+	return res
 }
 
 func (m *Model) Duration() time.Duration {
-	return m.FinishTime.Sub(m.StartTime).Round(time.Second)
+	return (m.FinishTime.Sub(m.StartTime) - m.PausesDuration()).Round(time.Second)
 }
 
 func (m *Model) ActivityDuration() time.Duration {
-	return time.Now().Sub(m.StartTime).Round(time.Second)
+	return (time.Now().Sub(m.StartTime) - m.PausesDuration()).Round(time.Second)
 }
 
+// TimelineVersion is the current version of stored timeline.
+const TimelineVersion = 2
+
 type Timeline struct {
+	Version int
+	// Seq is a sequence number of the last event of log applied to timeline.
+	Seq  int
 	List []*Model
 }
 
+// marshalTimeline encodes timeline with the current version.
+func marshalTimeline(t *Timeline) ([]byte, error) {
+	t.Version = TimelineVersion
+	return json.Marshal(t)
+}
+
 func (t *Timeline) GetCurrent() (*Model, error) {
 	if len(t.List) == 0 {
 		return nil, ErrTimelineEmpty
@@ -57,28 +145,208 @@ func (t *Timeline) Add(m *Model) {
 	t.List = append(t.List, m)
 }
 
+// Insert finished record to chronological position, returns the number of record.
+func (t *Timeline) Insert(m *Model) (int, error) {
+	if !m.IsFinished() {
+		return 0, errors.New("only finished record can be inserted")
+	}
+	if m.FinishTime.Sub(m.StartTime) <= 0 {
+		return 0, errors.New("finish time must be after start time")
+	}
+
+	num := len(t.List)
+	for i, r := range t.List {
+		if r.StartTime.Sub(m.StartTime) > 0 {
+			num = i
+			break
+		}
+	}
+	if num > 0 {
+		prev := t.List[num-1]
+		if !prev.IsFinished() || m.StartTime.Sub(prev.FinishTime) < 0 {
+			return 0, ErrOverlap
+		}
+	}
+	if num < len(t.List) && t.List[num].StartTime.Sub(m.FinishTime) < 0 {
+		return 0, ErrOverlap
+	}
+
+	t.List = append(t.List, nil)
+	copy(t.List[num+1:], t.List[num:])
+	t.List[num] = m
+
+	return num, nil
+}
+
+// Remove record from timeline, published record can't be removed.
+func (t *Timeline) Remove(num int) error {
+	if num < 0 || num >= len(t.List) {
+		return ErrBadRecordNum
+	}
+	if t.List[num].IsPublished() {
+		return ErrPublished
+	}
+	t.List = append(t.List[:num], t.List[num+1:]...)
+	return nil
+}
+
+// Split record to two records at time, the second record is started at this time for issue.
+func (t *Timeline) Split(num int, at time.Time, issue *Issue) error {
+	if num < 0 || num >= len(t.List) {
+		return ErrBadRecordNum
+	}
+	m := t.List[num]
+	if m.IsPublished() {
+		return ErrPublished
+	}
+	if at.Sub(m.StartTime) <= 0 {
+		return errors.New("split time must be after start time of record")
+	}
+	if m.IsFinished() && m.FinishTime.Sub(at) <= 0 {
+		return errors.New("split time must be before finish time of record")
+	}
+	if !m.IsFinished() && at.After(time.Now()) {
+		return errors.New("split time must be in the past")
+	}
+
+	first := make([]*Pause, 0)
+	second := make([]*Pause, 0)
+	for _, p := range m.Pauses {
+		switch {
+		case p.IsFinished() && p.FinishTime.Sub(at) <= 0:
+			first = append(first, p)
+		case p.StartTime.Sub(at) >= 0:
+			second = append(second, p)
+		default:
+			return errors.New("can't split record inside pause")
+		}
+	}
+
+	next := &Model{
+		Finished:    m.Finished,
+		StartTime:   at,
+		FinishTime:  m.FinishTime,
+		Description: m.Description,
+		Issue:       issue,
+		Tag:         m.Tag,
+		Profile:     m.Profile,
+		Pauses:      second,
+	}
+	m.Finished = true
+	m.FinishTime = at
+	m.Pauses = first
+
+	t.List = append(t.List, nil)
+	copy(t.List[num+2:], t.List[num+1:])
+	t.List[num+1] = next
+
+	return nil
+}
+
+// find returns number of record with the same start time and issue as model, -1 if there is no such record.
+func (t *Timeline) find(m *Model) int {
+	for i, r := range t.List {
+		if r.StartTime.Equal(m.StartTime) && r.Issue.Key == m.Issue.Key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Merge two adjacent records of the same issue, the gap between records becomes a pause.
+func (t *Timeline) Merge(num int, nextNum int) error {
+	if num < 0 || nextNum >= len(t.List) {
+		return ErrBadRecordNum
+	}
+	if nextNum != num+1 {
+		return errors.New("only adjacent records can be merged")
+	}
+	m, next := t.List[num], t.List[nextNum]
+	if m.IsPublished() || next.IsPublished() {
+		return ErrPublished
+	}
+	if m.Issue.Key != next.Issue.Key {
+		return errors.New("only records of the same issue can be merged")
+	}
+	if m.GetProfile() != next.GetProfile() {
+		return errors.New("can't merge records of different profiles")
+	}
+	if len(m.Tag) > 0 && len(next.Tag) > 0 && m.Tag != next.Tag {
+		return errors.New("can't merge records with different tags")
+	}
+
+	if next.StartTime.Sub(m.FinishTime) > 0 {
+		m.Pauses = append(m.Pauses, &Pause{StartTime: m.FinishTime, FinishTime: next.StartTime})
+	}
+	m.Pauses = append(m.Pauses, next.Pauses...)
+	m.Finished = next.Finished
+	m.FinishTime = next.FinishTime
+	if len(m.Tag) == 0 {
+		m.Tag = next.Tag
+	}
+	switch {
+	case len(m.Description) == 0:
+		m.Description = next.Description
+	case len(next.Description) > 0 && m.Description != next.Description:
+		m.Description += "; " + next.Description
+	}
+
+	return t.Remove(nextNum)
+}
+
 type DurationDescription struct {
 	Duration time.Duration
-	Summary  string
+	// Rounded is a sum of rounded durations of finished records which will be sent.
+	Rounded time.Duration
+	Summary string
+	Profile string
 }
 
-func (t *Timeline) GetDurationsByTasks() map[string]DurationDescription {
-	res := make(map[string]DurationDescription)
-	for _, task := range t.List {
-		if _, ok := res[task.Issue.Key]; !ok {
-			res[task.Issue.Key] = DurationDescription{
-				Duration: time.Duration(0),
-				Summary:  task.Issue.Fields.Summary,
-			}
+// RecordGroup is numbers of records with the same key.
+type RecordGroup struct {
+	Key  string
+	Nums []int
+}
+
+// Group groups records by key in order of the first record of group, records with empty key are skipped.
+func (t *Timeline) Group(key func(num int, m *Model) string) []*RecordGroup {
+	res := make([]*RecordGroup, 0)
+	groups := make(map[string]*RecordGroup)
+	for i, m := range t.List {
+		k := key(i, m)
+		if len(k) == 0 {
+			continue
 		}
-		m := res[task.Issue.Key]
-		if task.IsFinished() {
-			m.Duration += task.Duration()
-		} else {
-			m.Duration += task.ActivityDuration()
+		if g, ok := groups[k]; ok {
+			g.Nums = append(g.Nums, i)
+			continue
 		}
-		res[task.Issue.Key] = m
+		groups[k] = &RecordGroup{Key: k, Nums: []int{i}}
+		res = append(res, groups[k])
+	}
+	return res
+}
 
+func (t *Timeline) GetDurationsByTasks(rules RulesByProfile) map[string]DurationDescription {
+	res := make(map[string]DurationDescription)
+	byIssue := t.Group(func(_ int, m *Model) string {
+		return m.Issue.Key
+	})
+	for _, g := range byIssue {
+		first := t.List[g.Nums[0]]
+		m := DurationDescription{Summary: first.Issue.Summary, Profile: first.GetProfile()}
+		for _, num := range g.Nums {
+			task := t.List[num]
+			if task.IsFinished() {
+				m.Duration += task.Duration()
+				if rounded, ok := rules(task.GetProfile()).Apply(task.Tag, task.Duration()); ok {
+					m.Rounded += rounded
+				}
+			} else {
+				m.Duration += task.ActivityDuration()
+			}
+		}
+		res[g.Key] = m
 	}
 	return res
 }
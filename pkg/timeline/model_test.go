@@ -0,0 +1,143 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_Duration_WithPauses_PausesExcluded(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.UTC)
+	m := &Model{
+		Finished:   true,
+		StartTime:  start,
+		FinishTime: start.Add(2 * time.Hour),
+		Pauses: []*Pause{
+			{StartTime: start.Add(10 * time.Minute), FinishTime: start.Add(40 * time.Minute)},
+			{StartTime: start.Add(time.Hour), FinishTime: start.Add(70 * time.Minute)},
+		},
+	}
+
+	assert.Equal(t, 40*time.Minute, m.PausesDuration())
+	assert.Equal(t, 80*time.Minute, m.Duration())
+}
+
+func TestModel_PauseResume(t *testing.T) {
+	m := NewModel(nil)
+
+	require.NoError(t, m.Pause())
+	assert.True(t, m.IsPaused())
+	assert.Equal(t, ErrPaused, m.Pause())
+
+	require.NoError(t, m.Resume())
+	assert.False(t, m.IsPaused())
+	assert.Equal(t, ErrNotPaused, m.Resume())
+	assert.Len(t, m.Pauses, 1)
+}
+
+func TestModel_Finish_Paused_FinishedAtPauseStart(t *testing.T) {
+	m := NewModel(nil)
+	require.NoError(t, m.Pause())
+	pauseStart := m.Pauses[0].StartTime
+
+	m.Finish()
+
+	assert.True(t, m.IsFinished())
+	assert.Equal(t, pauseStart, m.FinishTime)
+	assert.Empty(t, m.Pauses)
+}
+
+func finishedModel(start time.Time, dur time.Duration) *Model {
+	return &Model{Finished: true, StartTime: start, FinishTime: start.Add(dur)}
+}
+
+func TestTimeline_Insert(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.UTC)
+	tl := &Timeline{List: []*Model{
+		finishedModel(start, time.Hour),
+		finishedModel(start.Add(3*time.Hour), time.Hour),
+	}}
+
+	t.Run("between records", func(t *testing.T) {
+		num, err := tl.Insert(finishedModel(start.Add(time.Hour), time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, num)
+		assert.Len(t, tl.List, 3)
+	})
+
+	t.Run("overlaps previous record", func(t *testing.T) {
+		_, err := tl.Insert(finishedModel(start.Add(30*time.Minute), time.Minute))
+		assert.Equal(t, ErrOverlap, err)
+	})
+
+	t.Run("overlaps next record", func(t *testing.T) {
+		_, err := tl.Insert(finishedModel(start.Add(150*time.Minute), time.Hour))
+		assert.Equal(t, ErrOverlap, err)
+	})
+
+	t.Run("after running record", func(t *testing.T) {
+		tl.Add(&Model{StartTime: start.Add(5 * time.Hour)})
+		_, err := tl.Insert(finishedModel(start.Add(6*time.Hour), time.Minute))
+		assert.Equal(t, ErrOverlap, err)
+	})
+}
+
+func TestTimeline_Remove_Published(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.UTC)
+	published := finishedModel(start, time.Hour)
+	published.WorklogID = "1"
+	tl := &Timeline{List: []*Model{published, finishedModel(start.Add(time.Hour), time.Hour)}}
+
+	assert.Equal(t, ErrPublished, tl.Remove(0))
+	require.NoError(t, tl.Remove(1))
+	assert.Len(t, tl.List, 1)
+}
+
+func TestTimeline_SplitMerge(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.UTC)
+	issue := &Issue{Key: "A-1"}
+	m := finishedModel(start, 2*time.Hour)
+	m.Issue = issue
+	m.Pauses = []*Pause{{StartTime: start.Add(90 * time.Minute), FinishTime: start.Add(100 * time.Minute)}}
+	tl := &Timeline{List: []*Model{m}}
+
+	assert.Error(t, tl.Split(0, start.Add(95*time.Minute), issue), "split inside pause")
+
+	require.NoError(t, tl.Split(0, start.Add(time.Hour), issue))
+	require.Len(t, tl.List, 2)
+	assert.Equal(t, time.Hour, tl.List[0].Duration())
+	assert.Equal(t, 50*time.Minute, tl.List[1].Duration())
+
+	tl.List[0].FinishTime = start.Add(50 * time.Minute)
+	require.NoError(t, tl.Merge(0, 1))
+	require.Len(t, tl.List, 1)
+	assert.Equal(t, 100*time.Minute, tl.List[0].Duration())
+	assert.Len(t, tl.List[0].Pauses, 2)
+}
+
+func TestTimeline_Split_ProfileKept(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.UTC)
+	m := finishedModel(start, 2*time.Hour)
+	m.Issue = &Issue{Key: "A-1"}
+	m.Profile = "work"
+	tl := &Timeline{List: []*Model{m}}
+
+	require.NoError(t, tl.Split(0, start.Add(time.Hour), &Issue{Key: "A-2"}))
+	require.Len(t, tl.List, 2)
+	assert.Equal(t, "work", tl.List[1].Profile)
+}
+
+func TestTimeline_Merge_DifferentProfiles(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.UTC)
+	first := finishedModel(start, time.Hour)
+	next := finishedModel(start.Add(time.Hour), time.Hour)
+	first.Issue = &Issue{Key: "A-1"}
+	next.Issue = first.Issue
+	next.Profile = "work"
+	tl := &Timeline{List: []*Model{first, next}}
+
+	assert.Error(t, tl.Merge(0, 1))
+	assert.Len(t, tl.List, 2)
+}
@@ -0,0 +1,270 @@
+package timeline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrskom/jwa-console/pkg/rounding"
+)
+
+const (
+	SkipReasonPublished   = "already published"
+	SkipReasonNotFinished = "not finished"
+	SkipReasonShort       = "duration less than minimum"
+	SkipReasonRoundedZero = "duration rounded to zero"
+)
+
+// PlanItem is a worklog which will be sent for record or the reason why record is skipped.
+type PlanItem struct {
+	Profile          string    `json:"profile" yaml:"profile"`
+	Num              int       `json:"num" yaml:"num"`
+	Records          []int     `json:"records" yaml:"records"`
+	IssueKey         string    `json:"issueKey" yaml:"issueKey"`
+	IssueID          string    `json:"issueId" yaml:"issueId"`
+	Started          time.Time `json:"started" yaml:"started"`
+	DurationSeconds  int       `json:"durationSeconds" yaml:"durationSeconds"`
+	TimeSpentSeconds int       `json:"timeSpentSeconds" yaml:"timeSpentSeconds"`
+	Comment          string    `json:"comment" yaml:"comment"`
+	SkipReason       string    `json:"skipReason,omitempty" yaml:"skipReason,omitempty"`
+}
+
+func (i *PlanItem) IsSkipped() bool {
+	return len(i.SkipReason) > 0
+}
+
+// Plan of publishing, contains item for every record of timeline.
+type Plan struct {
+	Items []*PlanItem `json:"items" yaml:"items"`
+}
+
+// RunningPolicy defines what publishing does with the not finished record.
+type RunningPolicy string
+
+const (
+	// RunningKeep leaves the running record in timeline untouched.
+	RunningKeep RunningPolicy = "keep"
+	// RunningStop stops the running record and publishes it.
+	RunningStop RunningPolicy = "stop"
+	// RunningSplit publishes the running record up to now and keeps tracking in a new record.
+	RunningSplit RunningPolicy = "split"
+)
+
+func ParseRunningPolicy(val string) (RunningPolicy, error) {
+	switch p := RunningPolicy(val); p {
+	case RunningKeep, RunningStop, RunningSplit:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unexpected running policy '%s', expected one of: keep, stop, split", val)
+	}
+}
+
+// applyRunningPolicy prepares the running record for publishing, if it's matched by filter.
+func applyRunningPolicy(t *Timeline, f *PublishFilter, p RunningPolicy) error {
+	num := len(t.List) - 1
+	if num < 0 || t.List[num].IsFinished() || !f.Match(num, t.List[num]) {
+		return nil
+	}
+	m := t.List[num]
+	switch p {
+	case RunningStop:
+		m.Finish()
+	case RunningSplit:
+		at := time.Now()
+		if m.IsPaused() {
+			at = m.Pauses[len(m.Pauses)-1].StartTime
+		}
+		return t.Split(num, at, m.Issue)
+	}
+	return nil
+}
+
+// RecordRange is an inclusive range of record numbers.
+type RecordRange struct {
+	From int
+	To   int
+}
+
+// PublishFilter selects records for publishing, empty fields match all records.
+type PublishFilter struct {
+	Records *RecordRange
+	// From is an inclusive lower bound of record start time.
+	From time.Time
+	// Until is an exclusive upper bound of record start time.
+	Until  time.Time
+	Issues []string
+	Tags   []string
+}
+
+func (f *PublishFilter) Match(num int, m *Model) bool {
+	if f == nil {
+		return true
+	}
+	if f.Records != nil && (num < f.Records.From || num > f.Records.To) {
+		return false
+	}
+	if !f.From.IsZero() && m.StartTime.Before(f.From) {
+		return false
+	}
+	if !f.Until.IsZero() && !m.StartTime.Before(f.Until) {
+		return false
+	}
+	if len(f.Issues) > 0 && !contains(f.Issues, m.Issue.Key) {
+		return false
+	}
+	if len(f.Tags) > 0 && !contains(f.Tags, m.Tag) {
+		return false
+	}
+	return true
+}
+
+func contains(list []string, val string) bool {
+	for _, v := range list {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// PublishOpts are options of publishing.
+type PublishOpts struct {
+	// Filter selects records, nil filter matches all records.
+	Filter  *PublishFilter
+	Running RunningPolicy
+	// Aggregate merges records of the same issue per day into one worklog.
+	Aggregate bool
+}
+
+// RulesByProfile returns rules of rounding for profile.
+type RulesByProfile func(profile string) *rounding.Rules
+
+// BuildPlan builds plan for records matched by filter, time spent of worklogs is rounded by rules.
+func BuildPlan(t *Timeline, opts PublishOpts, rules RulesByProfile) *Plan {
+	groups := t.Group(func(num int, m *Model) string {
+		switch {
+		case !opts.Filter.Match(num, m):
+			return ""
+		case !opts.Aggregate || m.IsPublished() || !m.IsFinished():
+			return strconv.Itoa(num)
+		default:
+			return m.GetProfile() + " " + m.Issue.Key + " " + m.StartTime.Local().Format("2006-01-02")
+		}
+	})
+
+	plan := &Plan{Items: make([]*PlanItem, 0, len(groups))}
+	for _, g := range groups {
+		plan.Items = append(plan.Items, buildPlanItem(t, g.Nums, rules(t.List[g.Nums[0]].GetProfile())))
+	}
+	return plan
+}
+
+// buildPlanItem builds one worklog for records, the records must be of the same issue and profile.
+func buildPlanItem(t *Timeline, nums []int, rules *rounding.Rules) *PlanItem {
+	first := t.List[nums[0]]
+	item := &PlanItem{
+		Profile:  first.GetProfile(),
+		Num:      nums[0],
+		Records:  nums,
+		IssueKey: first.Issue.Key,
+		IssueID:  first.Issue.ID,
+		Started:  first.StartTime.Round(time.Second),
+	}
+	switch {
+	case first.IsPublished():
+		item.SkipReason = SkipReasonPublished
+	case !first.IsFinished():
+		item.SkipReason = SkipReasonNotFinished
+		item.DurationSeconds = int(first.ActivityDuration().Seconds())
+		return item
+	}
+
+	duration := time.Duration(0)
+	tags := make([]string, 0, 1)
+	descriptions := make([]string, 0, len(nums))
+	for _, num := range nums {
+		m := t.List[num]
+		duration += m.Duration()
+		if len(m.Tag) > 0 && !contains(tags, m.Tag) {
+			tags = append(tags, m.Tag)
+		}
+		if len(m.Description) > 0 && !contains(descriptions, m.Description) {
+			descriptions = append(descriptions, m.Description)
+		}
+	}
+	item.DurationSeconds = int(duration.Seconds())
+
+	comment := strings.Join(descriptions, "; ")
+	for i := len(tags) - 1; i >= 0; i-- {
+		comment = "#" + tags[i] + " " + comment
+	}
+	item.Comment = comment
+
+	tag := ""
+	if len(tags) == 1 {
+		tag = tags[0]
+	}
+	rounded, ok := rules.Apply(tag, duration)
+	item.TimeSpentSeconds = int(rounded.Seconds())
+	switch {
+	case item.IsSkipped():
+	case !ok && duration < rules.MinDuration:
+		item.SkipReason = SkipReasonShort
+	case !ok:
+		item.SkipReason = SkipReasonRoundedZero
+	}
+	return item
+}
+
+// Sendable returns items which will be sent.
+func (p *Plan) Sendable() []*PlanItem {
+	res := make([]*PlanItem, 0, len(p.Items))
+	for _, item := range p.Items {
+		if !item.IsSkipped() {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// Plan builds publishing plan by current timeline, nothing is sent or saved.
+func (c *Component) Plan(opts PublishOpts) (*Plan, error) {
+	tl, err := c.getTimeline()
+	if err != nil {
+		return nil, err
+	}
+	if err := applyRunningPolicy(tl, opts.Filter, opts.Running); err != nil {
+		return nil, err
+	}
+	rules, err := c.RoundingFor(tl)
+	if err != nil {
+		return nil, err
+	}
+	return BuildPlan(tl, opts, rules), nil
+}
+
+// RoundingFor returns configured rules of worklogs rounding for profiles of timeline.
+func (c *Component) RoundingFor(t *Timeline) (RulesByProfile, error) {
+	byProfile := make(map[string]*rounding.Rules)
+	for _, m := range t.List {
+		if _, ok := byProfile[m.GetProfile()]; ok {
+			continue
+		}
+		cfg, err := c.cfg.GetCfgFor(m.GetProfile())
+		if err != nil {
+			return nil, err
+		}
+		rules, err := cfg.Rounding()
+		if err != nil {
+			return nil, err
+		}
+		byProfile[m.GetProfile()] = rules
+	}
+	return func(profileName string) *rounding.Rules {
+		if rules, ok := byProfile[profileName]; ok {
+			return rules
+		}
+		return rounding.Default()
+	}, nil
+}
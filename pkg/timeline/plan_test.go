@@ -0,0 +1,114 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/rounding"
+)
+
+func staticRules(rules *rounding.Rules) RulesByProfile {
+	return func(string) *rounding.Rules {
+		return rules
+	}
+}
+
+func TestBuildPlan(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 400, time.UTC)
+	published := issueModel("A-1", start, time.Hour)
+	published.WorklogID = "1"
+	tagged := issueModel("A-2", start.Add(time.Hour), 90*time.Minute)
+	tagged.Tag = "review"
+	tagged.Description = "pr"
+
+	plan := BuildPlan(&Timeline{List: []*Model{
+		published,
+		tagged,
+		issueModel("A-3", start.Add(3*time.Hour), 59*time.Second),
+		{StartTime: start.Add(4 * time.Hour), Issue: issueModel("A-4", start, 0).Issue},
+	}}, PublishOpts{}, staticRules(&rounding.Rules{
+		Default:     rounding.Rule{Step: 5 * time.Minute, Mode: rounding.ModeNearest},
+		MinDuration: time.Minute,
+		ByTag:       map[string]rounding.Rule{"review": {Step: time.Hour, Mode: rounding.ModeUp}},
+	}))
+
+	require.Len(t, plan.Items, 4)
+	assert.Equal(t, SkipReasonPublished, plan.Items[0].SkipReason)
+	assert.Equal(t, &PlanItem{
+		Profile:          "default",
+		Num:              1,
+		Records:          []int{1},
+		IssueKey:         "A-2",
+		Started:          start.Add(time.Hour).Round(time.Second),
+		DurationSeconds:  5400,
+		TimeSpentSeconds: 7200,
+		Comment:          "#review pr",
+	}, plan.Items[1])
+	assert.Equal(t, SkipReasonShort, plan.Items[2].SkipReason)
+	assert.Equal(t, SkipReasonNotFinished, plan.Items[3].SkipReason)
+	assert.Len(t, plan.Sendable(), 1)
+}
+
+func TestPublishFilter_Match(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.UTC)
+	m := issueModel("A-1", start, time.Hour)
+	m.Tag = "review"
+
+	tests := []struct {
+		name   string
+		filter *PublishFilter
+		match  bool
+	}{
+		{"nil filter", nil, true},
+		{"in records range", &PublishFilter{Records: &RecordRange{From: 0, To: 2}}, true},
+		{"out of records range", &PublishFilter{Records: &RecordRange{From: 3, To: 5}}, false},
+		{"until the day", &PublishFilter{Until: start.Truncate(24 * time.Hour)}, false},
+		{"until the next day", &PublishFilter{Until: start.Truncate(24 * time.Hour).Add(24 * time.Hour)}, true},
+		{"from later", &PublishFilter{From: start.Add(time.Minute)}, false},
+		{"other issue", &PublishFilter{Issues: []string{"A-2"}}, false},
+		{"the tag", &PublishFilter{Tags: []string{"dev", "review"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, tt.filter.Match(1, m))
+		})
+	}
+}
+
+func TestBuildPlan_Aggregate(t *testing.T) {
+	start := time.Date(2019, 7, 7, 10, 0, 0, 0, time.Local)
+	first := issueModel("A-1", start, 40*time.Second)
+	first.Description = "fix"
+	first.Tag = "dev"
+	second := issueModel("A-1", start.Add(time.Hour), 40*time.Second)
+	second.Description = "review"
+	second.Tag = "review"
+	third := issueModel("A-1", start.Add(2*time.Hour), time.Hour)
+	third.Description = "fix"
+	nextDay := issueModel("A-1", start.Add(24*time.Hour), time.Hour)
+
+	plan := BuildPlan(&Timeline{List: []*Model{
+		first,
+		issueModel("A-2", start.Add(30*time.Minute), time.Minute),
+		second,
+		third,
+		nextDay,
+	}}, PublishOpts{Aggregate: true}, staticRules(rounding.Default()))
+
+	require.Len(t, plan.Items, 3)
+	assert.Equal(t, &PlanItem{
+		Profile:          "default",
+		Num:              0,
+		Records:          []int{0, 2, 3},
+		IssueKey:         "A-1",
+		Started:          start,
+		DurationSeconds:  3680,
+		TimeSpentSeconds: 3680,
+		Comment:          "#dev #review fix; review",
+	}, plan.Items[0])
+	assert.Equal(t, []int{1}, plan.Items[1].Records)
+	assert.Equal(t, []int{4}, plan.Items[2].Records)
+}
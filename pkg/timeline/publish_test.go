@@ -0,0 +1,264 @@
+package timeline
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/creds"
+	"github.com/andrskom/jwa-console/pkg/filedb"
+	"github.com/andrskom/jwa-console/pkg/internal/testjira"
+	"github.com/andrskom/jwa-console/pkg/issuecache"
+)
+
+func getTestComponentWithJira(t *testing.T, j *testjira.Server) *Component {
+	c := getTestComponent(t)
+	c.jiraFactory = testjira.NewFactory(t, c.db, c.profiles, j)
+	c.issues = issuecache.NewComponent(c.db, c.jiraFactory, c.cfg)
+
+	return c
+}
+
+func issueModel(key string, start time.Time, dur time.Duration) *Model {
+	m := finishedModel(start, dur)
+	m.Issue = &Issue{Key: key}
+	return m
+}
+
+func TestComponent_Publish_FailedAndRepeated_NoDoublePosts(t *testing.T) {
+	j := &testjira.Server{Limit: 0}
+	c := getTestComponentWithJira(t, j)
+
+	start := time.Now().Add(-5 * time.Hour)
+	tl := &Timeline{List: []*Model{
+		issueModel("A-1", start, time.Hour),
+		issueModel("A-2", start.Add(time.Hour), 30*time.Second),
+		issueModel("A-3", start.Add(2*time.Hour), time.Hour),
+		{StartTime: start.Add(4 * time.Hour), Issue: &Issue{Key: "A-4"}},
+	}}
+	require.NoError(t, c.saveTimeline(tl, EventAdd))
+
+	require.Error(t, c.Publish(PublishOpts{Running: RunningKeep}))
+	j.Limit = -1
+	require.NoError(t, c.Publish(PublishOpts{Running: RunningKeep}))
+	require.NoError(t, c.Publish(PublishOpts{Running: RunningKeep}))
+
+	assert.Equal(t, []string{"/rest/api/2/issue/A-1/worklog", "/rest/api/2/issue/A-3/worklog"}, j.Worklogs)
+
+	rest, err := c.Get()
+	require.NoError(t, err)
+	require.Len(t, rest.List, 2)
+	assert.Equal(t, "A-2", rest.List[0].Issue.Key)
+	assert.False(t, rest.List[0].IsPublished())
+	assert.Equal(t, "A-4", rest.List[1].Issue.Key)
+
+	published, err := c.GetArchive(&PublishFilter{From: start, Until: time.Now()})
+	require.NoError(t, err)
+	require.Len(t, published.List, 2)
+	assert.Equal(t, "1", published.List[0].WorklogID)
+	assert.Equal(t, "2", published.List[1].WorklogID)
+}
+
+func TestComponent_Publish_PartialFailure_SentRecordsKeepIDs(t *testing.T) {
+	j := &testjira.Server{Limit: 1}
+	c := getTestComponentWithJira(t, j)
+
+	start := time.Now().Add(-5 * time.Hour)
+	require.NoError(t, c.saveTimeline(&Timeline{List: []*Model{
+		issueModel("A-1", start, time.Hour),
+		issueModel("A-2", start.Add(time.Hour), time.Hour),
+	}}, EventAdd))
+
+	require.Error(t, c.Publish(PublishOpts{Running: RunningKeep}))
+	tl, err := c.Get()
+	require.NoError(t, err)
+	require.Len(t, tl.List, 2)
+	assert.Equal(t, "1", tl.List[0].WorklogID)
+	assert.False(t, tl.List[1].IsPublished())
+}
+
+func TestComponent_Publish_Filter_NotMatchedRecordsStay(t *testing.T) {
+	j := &testjira.Server{Limit: -1}
+	c := getTestComponentWithJira(t, j)
+
+	start := time.Now().Add(-5 * time.Hour)
+	require.NoError(t, c.saveTimeline(&Timeline{List: []*Model{
+		issueModel("A-1", start, time.Hour),
+		issueModel("A-2", start.Add(time.Hour), time.Hour),
+		issueModel("A-1", start.Add(2*time.Hour), time.Hour),
+	}}, EventAdd))
+
+	require.NoError(t, c.Publish(PublishOpts{Filter: &PublishFilter{Issues: []string{"A-1"}}, Running: RunningKeep}))
+
+	assert.Len(t, j.Worklogs, 2)
+	tl, err := c.Get()
+	require.NoError(t, err)
+	require.Len(t, tl.List, 1)
+	assert.Equal(t, "A-2", tl.List[0].Issue.Key)
+}
+
+func TestComponent_Publish_RunningPolicy(t *testing.T) {
+	start := time.Now().Add(-3 * time.Hour)
+
+	tests := []struct {
+		name     string
+		policy   RunningPolicy
+		worklogs int
+		running  bool
+		rest     int
+	}{
+		{"keep", RunningKeep, 1, true, 1},
+		{"stop", RunningStop, 2, false, 0},
+		{"split", RunningSplit, 2, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &testjira.Server{Limit: -1}
+			c := getTestComponentWithJira(t, j)
+			running := issueModel("A-2", start.Add(time.Hour), 0)
+			running.Finished = false
+			running.FinishTime = time.Time{}
+			require.NoError(t, c.saveTimeline(&Timeline{List: []*Model{
+				issueModel("A-1", start, time.Hour),
+				running,
+			}}, EventAdd))
+
+			require.NoError(t, c.Publish(PublishOpts{Running: tt.policy}))
+
+			assert.Len(t, j.Worklogs, tt.worklogs)
+			cur, err := c.GetCurrent()
+			if tt.rest == 0 {
+				assert.Equal(t, ErrTimelineEmpty, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "A-2", cur.Issue.Key)
+			assert.Equal(t, tt.running, !cur.IsFinished())
+			assert.False(t, cur.IsPublished())
+		})
+	}
+}
+
+func TestComponent_Plan_RunningPolicy_TimelineNotChanged(t *testing.T) {
+	c := getTestComponent(t)
+	running := issueModel("A-1", time.Now().Add(-time.Hour), 0)
+	running.Finished = false
+	require.NoError(t, c.saveTimeline(&Timeline{List: []*Model{running}}, EventAdd))
+
+	plan, err := c.Plan(PublishOpts{Running: RunningSplit})
+	require.NoError(t, err)
+	require.Len(t, plan.Items, 2)
+	assert.False(t, plan.Items[0].IsSkipped())
+	assert.Equal(t, SkipReasonNotFinished, plan.Items[1].SkipReason)
+
+	tl, err := c.Get()
+	require.NoError(t, err)
+	require.Len(t, tl.List, 1)
+	assert.False(t, tl.List[0].IsFinished())
+}
+
+func TestComponent_Publish_Profiles_RoutedToProfileJira(t *testing.T) {
+	defaultJira := &testjira.Server{Limit: -1}
+	c := getTestComponentWithJira(t, defaultJira)
+
+	clientJira := &testjira.Server{Limit: -1}
+	require.NoError(t, c.profiles.Add("client"))
+	require.NoError(t, c.jiraFactory.GetCredsComponent().SaveFor("client", &creds.Model{Addr: testjira.Start(t, clientJira)}))
+	require.NoError(t, c.profiles.SetOverride("client"))
+	require.NoError(t, c.cfg.Init())
+
+	start := time.Now().Add(-5 * time.Hour)
+	clientModel := issueModel("C-1", start.Add(time.Hour), time.Hour)
+	clientModel.Profile = "client"
+	require.NoError(t, c.saveTimeline(&Timeline{List: []*Model{
+		issueModel("A-1", start, time.Hour),
+		clientModel,
+	}}, EventAdd))
+
+	require.NoError(t, c.Publish(PublishOpts{Running: RunningKeep}))
+
+	assert.Equal(t, []string{"/rest/api/2/issue/A-1/worklog"}, defaultJira.Worklogs)
+	assert.Equal(t, []string{"/rest/api/2/issue/C-1/worklog"}, clientJira.Worklogs)
+}
+
+func TestComponent_Publish_DroppedConnection_PartialPublish(t *testing.T) {
+	j := &testjira.Server{Limit: 1, Dropping: true}
+	c := getTestComponentWithJira(t, j)
+
+	start := time.Now().Add(-5 * time.Hour)
+	require.NoError(t, c.saveTimeline(&Timeline{List: []*Model{
+		issueModel("A-1", start, time.Hour),
+		issueModel("A-2", start.Add(time.Hour), time.Hour),
+	}}, EventAdd))
+
+	err := c.Publish(PublishOpts{Running: RunningKeep})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "records 0 are published")
+	tl, err := c.Get()
+	require.NoError(t, err)
+	require.Len(t, tl.List, 2)
+	assert.Equal(t, "1", tl.List[0].WorklogID)
+	assert.False(t, tl.List[1].IsPublished())
+}
+
+func TestComponent_Publish_JiraRequestedWithoutLock(t *testing.T) {
+	j := &testjira.Server{Limit: -1}
+	c := getTestComponentWithJira(t, j)
+	other, err := filedb.InitJSONWithDir(c.db.DirPath())
+	require.NoError(t, err)
+	lockErrs := make([]error, 0)
+	j.Hook = func(*http.Request) {
+		err := other.Lock()
+		if err == nil {
+			other.Unlock()
+		}
+		lockErrs = append(lockErrs, err)
+	}
+
+	start := time.Now().Add(-5 * time.Hour)
+	require.NoError(t, c.saveTimeline(&Timeline{List: []*Model{
+		issueModel("A-1", start, time.Hour),
+		issueModel("A-2", start.Add(time.Hour), time.Hour),
+	}}, EventAdd))
+
+	require.NoError(t, c.Publish(PublishOpts{Running: RunningKeep}))
+	assert.Len(t, j.Worklogs, 2)
+	assert.NotEmpty(t, lockErrs)
+	for _, err := range lockErrs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestComponent_Publish_TimelineChangedWhileSending_IDsKept(t *testing.T) {
+	j := &testjira.Server{Limit: -1}
+	c := getTestComponentWithJira(t, j)
+
+	start := time.Now().Add(-5 * time.Hour)
+	require.NoError(t, c.saveTimeline(&Timeline{List: []*Model{
+		issueModel("A-1", start.Add(time.Hour), time.Hour),
+		issueModel("A-2", start.Add(2*time.Hour), time.Hour),
+	}}, EventAdd))
+	j.Hook = func(r *http.Request) {
+		if r.Method != http.MethodPost {
+			return
+		}
+		j.Hook = nil
+		_, err := c.Add(issueModel("A-3", start, 30*time.Minute))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, c.Publish(PublishOpts{Running: RunningKeep, Filter: &PublishFilter{Issues: []string{"A-1", "A-2"}}}))
+	require.NoError(t, c.Publish(PublishOpts{Running: RunningKeep}))
+
+	assert.Equal(t, []string{
+		"/rest/api/2/issue/A-1/worklog",
+		"/rest/api/2/issue/A-2/worklog",
+		"/rest/api/2/issue/A-3/worklog",
+	}, j.Worklogs)
+	rest, err := c.Get()
+	require.NoError(t, err)
+	assert.Empty(t, rest.List)
+}
@@ -0,0 +1,181 @@
+package timeline
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReportPeriod is a period of time report.
+type ReportPeriod string
+
+const (
+	PeriodDay   ReportPeriod = "day"
+	PeriodWeek  ReportPeriod = "week"
+	PeriodMonth ReportPeriod = "month"
+)
+
+func ParseReportPeriod(val string) (ReportPeriod, error) {
+	switch p := ReportPeriod(val); p {
+	case PeriodDay, PeriodWeek, PeriodMonth:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unexpected period '%s', expected one of: day, week, month", val)
+	}
+}
+
+// Range returns inclusive start and exclusive end of period which contains date, week starts on Monday.
+func (p ReportPeriod) Range(date time.Time) (time.Time, time.Time) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	switch p {
+	case PeriodWeek:
+		from := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return from, from.AddDate(0, 0, 7)
+	case PeriodMonth:
+		from := day.AddDate(0, 0, 1-day.Day())
+		return from, from.AddDate(0, 1, 0)
+	default:
+		return day, day.AddDate(0, 0, 1)
+	}
+}
+
+// Columns of report are days of period, columns of month report are weeks.
+func (p ReportPeriod) Columns(from time.Time, until time.Time) []*ReportColumn {
+	res := make([]*ReportColumn, 0)
+	for start := from; start.Before(until); {
+		end := start.AddDate(0, 0, 1)
+		if p == PeriodMonth {
+			_, end = PeriodWeek.Range(start)
+			if end.After(until) {
+				end = until
+			}
+		}
+		res = append(res, &ReportColumn{From: start, Until: end})
+		start = end
+	}
+	return res
+}
+
+// ReportColumn is a part of period, records are matched by start time.
+type ReportColumn struct {
+	From  time.Time
+	Until time.Time
+}
+
+// ReportRow is a duration of records with the same key by columns.
+type ReportRow struct {
+	Key     string
+	Summary string
+	// Durations by columns of report.
+	Durations []time.Duration
+	Total     time.Duration
+}
+
+// ReportSection groups records by one of dimensions: issue, project, epic, tag or day for month report.
+type ReportSection struct {
+	Name   string
+	Rows   []*ReportRow
+	Totals []time.Duration
+	Total  time.Duration
+}
+
+// Report is a sum of activity by columns of period.
+type Report struct {
+	Period   ReportPeriod
+	From     time.Time
+	Until    time.Time
+	Columns  []*ReportColumn
+	Sections []*ReportSection
+}
+
+// reportDimension returns key of record and summary of key.
+type reportDimension struct {
+	name string
+	key  func(m *Model) (string, string)
+}
+
+const (
+	dayDimension = "Day"
+	// noKey is a key of records which don't have value of dimension.
+	noKey = "-"
+)
+
+var reportDimensions = []reportDimension{
+	{dayDimension, func(m *Model) (string, string) {
+		return m.StartTime.Format("2006-01-02"), m.StartTime.Format("Monday")
+	}},
+	{"Issue", func(m *Model) (string, string) {
+		return m.Issue.Key, m.Issue.Summary
+	}},
+	{"Project", func(m *Model) (string, string) {
+		return m.Issue.Project, ""
+	}},
+	{"Epic", func(m *Model) (string, string) {
+		return m.Issue.Epic, ""
+	}},
+	{"Tag", func(m *Model) (string, string) {
+		return m.Tag, ""
+	}},
+}
+
+// BuildReport sums activity of records started in period which contains date.
+// The running record is counted up to now.
+func BuildReport(models []*Model, period ReportPeriod, date time.Time) *Report {
+	from, until := period.Range(date)
+	r := &Report{Period: period, From: from, Until: until, Columns: period.Columns(from, until)}
+
+	for _, dim := range reportDimensions {
+		if dim.name == dayDimension && period != PeriodMonth {
+			// columns are days already
+			continue
+		}
+		s := &ReportSection{Name: dim.name, Rows: make([]*ReportRow, 0), Totals: make([]time.Duration, len(r.Columns))}
+		rows := make(map[string]*ReportRow)
+		for _, m := range models {
+			col := r.column(m.StartTime)
+			if col < 0 {
+				continue
+			}
+			dur := m.ActivityDuration()
+			if m.IsFinished() {
+				dur = m.Duration()
+			}
+			key, summary := dim.key(m)
+			if len(key) == 0 {
+				key = noKey
+			}
+			row, ok := rows[key]
+			if !ok {
+				row = &ReportRow{Key: key, Summary: summary, Durations: make([]time.Duration, len(r.Columns))}
+				rows[key] = row
+				s.Rows = append(s.Rows, row)
+			}
+			row.Durations[col] += dur
+			row.Total += dur
+			s.Totals[col] += dur
+			s.Total += dur
+		}
+		r.Sections = append(r.Sections, s)
+	}
+	return r
+}
+
+// column returns index of column which contains time, -1 if time is out of report.
+func (r *Report) column(at time.Time) int {
+	for i, col := range r.Columns {
+		if !at.Before(col.From) && at.Before(col.Until) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Report builds report of period which contains date over timeline and archive.
+func (c *Component) Report(period ReportPeriod, date time.Time) (*Report, error) {
+	from, until := period.Range(date)
+	tl, err := c.GetWithArchive(&PublishFilter{From: from, Until: until})
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildReport(tl.List, period, date), nil
+}
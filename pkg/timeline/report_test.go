@@ -0,0 +1,72 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportPeriod_Range(t *testing.T) {
+	date := time.Date(2019, 7, 7, 15, 0, 0, 0, time.UTC) // Sunday
+	tests := []struct {
+		period ReportPeriod
+		from   time.Time
+		until  time.Time
+		cols   int
+	}{
+		{PeriodDay, time.Date(2019, 7, 7, 0, 0, 0, 0, time.UTC), time.Date(2019, 7, 8, 0, 0, 0, 0, time.UTC), 1},
+		{PeriodWeek, time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 7, 8, 0, 0, 0, 0, time.UTC), 7},
+		{PeriodMonth, time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC), 5},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			from, until := tt.period.Range(date)
+			assert.Equal(t, tt.from, from)
+			assert.Equal(t, tt.until, until)
+			cols := tt.period.Columns(from, until)
+			assert.Len(t, cols, tt.cols)
+			assert.Equal(t, until, cols[len(cols)-1].Until)
+		})
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	start := time.Date(2019, 7, 1, 10, 0, 0, 0, time.UTC)
+	first := issueModel("A-1", start, time.Hour)
+	first.Issue.Project = "A"
+	first.Issue.Epic = "A-100"
+	first.Tag = "dev"
+	second := issueModel("A-1", start.AddDate(0, 0, 1), 30*time.Minute)
+	second.Issue.Project = "A"
+	other := issueModel("B-1", start.AddDate(0, 0, 1).Add(time.Hour), 2*time.Hour)
+	other.Issue.Project = "B"
+	outside := issueModel("A-1", start.AddDate(0, 0, 7), time.Hour)
+
+	r := BuildReport([]*Model{first, second, other, outside}, PeriodWeek, start)
+
+	require.Len(t, r.Columns, 7)
+	require.Len(t, r.Sections, 4)
+	issues := r.Sections[0]
+	assert.Equal(t, "Issue", issues.Name)
+	require.Len(t, issues.Rows, 2)
+	assert.Equal(t, "A-1", issues.Rows[0].Key)
+	assert.Equal(t, 90*time.Minute, issues.Rows[0].Total)
+	assert.Equal(t, time.Hour, issues.Rows[0].Durations[0])
+	assert.Equal(t, 30*time.Minute, issues.Rows[0].Durations[1])
+	assert.Equal(t, 150*time.Minute, issues.Totals[1])
+	assert.Equal(t, 210*time.Minute, issues.Total)
+
+	epics := r.Sections[2]
+	assert.Equal(t, "Epic", epics.Name)
+	require.Len(t, epics.Rows, 2)
+	assert.Equal(t, "A-100", epics.Rows[0].Key)
+	assert.Equal(t, noKey, epics.Rows[1].Key)
+	assert.Equal(t, 150*time.Minute, epics.Rows[1].Total)
+
+	month := BuildReport([]*Model{first, second, other, outside}, PeriodMonth, start)
+	assert.Equal(t, dayDimension, month.Sections[0].Name)
+	assert.Len(t, month.Sections[0].Rows, 3)
+	assert.Equal(t, 270*time.Minute, month.Sections[0].Total)
+}
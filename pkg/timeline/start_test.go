@@ -0,0 +1,79 @@
+package timeline
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrskom/jwa-console/pkg/config"
+	"github.com/andrskom/jwa-console/pkg/internal/testjira"
+	"github.com/andrskom/jwa-console/pkg/profile"
+)
+
+func TestComponent_Start_OfflineTransition_NoPanic(t *testing.T) {
+	j := &testjira.Server{Status: "Open"}
+	c := getTestComponentWithJira(t, j)
+	require.NoError(t, c.cfg.Update(func(m *config.Model) error {
+		m.AutoChangeStatusTo = IssueStatuNameInProgress
+		return nil
+	}))
+	m, err := c.BuildModel("A-1", nil)
+	require.NoError(t, err)
+
+	j.Offline = true
+	_, err = c.Start(m, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't get transitions of issue A-1")
+}
+
+func TestComponent_Start_TransitedIssue_StartedAgain(t *testing.T) {
+	j := &testjira.Server{Status: "Open"}
+	c := getTestComponentWithJira(t, j)
+	require.NoError(t, c.cfg.Update(func(m *config.Model) error {
+		m.StatusesForStart = []string{"Open", IssueStatuNameInProgress}
+		m.AutoChangeStatusTo = IssueStatuNameInProgress
+		return nil
+	}))
+
+	for i := 0; i < 2; i++ {
+		m, err := c.BuildModel("A-1", nil)
+		require.NoError(t, err)
+		m.StartTime = time.Now().Add(-time.Minute)
+		_, err = c.Start(m, nil)
+		require.NoError(t, err)
+		_, err = c.Stop()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, j.Transitions)
+
+	cached, err := c.issues.Lookup(profile.Default, "A-1")
+	require.NoError(t, err)
+	assert.Equal(t, IssueStatuNameInProgress, cached.Status)
+}
+
+func TestComponent_Start_TimelineChangedDuringTransition_Conflict(t *testing.T) {
+	j := &testjira.Server{Status: "Open"}
+	c := getTestComponentWithJira(t, j)
+	require.NoError(t, c.cfg.Update(func(m *config.Model) error {
+		m.AutoChangeStatusTo = IssueStatuNameInProgress
+		return nil
+	}))
+	m, err := c.BuildModel("A-1", nil)
+	require.NoError(t, err)
+
+	j.Hook = func(*http.Request) {
+		j.Hook = nil
+		_, err := c.Add(issueModel("A-2", time.Now().Add(-2*time.Hour), time.Hour))
+		require.NoError(t, err)
+	}
+	_, err = c.Start(m, nil)
+	assert.Equal(t, ErrConflict, err)
+
+	tl, err := c.Get()
+	require.NoError(t, err)
+	require.Len(t, tl.List, 1)
+	assert.Equal(t, "A-2", tl.List[0].Issue.Key)
+}